@@ -81,7 +81,10 @@ func main() {
 	// connect to database
 	db = database.ConnectPG()
 
-	r := fiber.New()
+	r := fiber.New(fiber.Config{
+		// leave room for a batch of attachments, per-file limits are enforced by the attachments module
+		BodyLimit: 200 * 1024 * 1024,
+	})
 
 	// Recover middleware
 	r.Use(recover.New())
@@ -114,7 +117,7 @@ func main() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     viper.GetString("CLIENT_DOMAIN"),
 		AllowMethods:     "GET, POST, PUT, PATCH, DELETE, OPTIONS",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Session-ID",
 		AllowCredentials: true,
 	}))
 
@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"errors"
+	"hubku/lapor_warga_be_v2/internal/modules/attachments"
+	"hubku/lapor_warga_be_v2/internal/modules/reports"
+	"hubku/lapor_warga_be_v2/pkg"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/cast"
+)
+
+type AttachmentsController struct {
+	service attachments.AttachmentsService
+}
+
+func NewAttachmentsController(s attachments.AttachmentsService) *AttachmentsController {
+	return &AttachmentsController{service: s}
+}
+
+func (c *AttachmentsController) UploadAttachments(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	reportID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid report id",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid multipart form",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	result, err := c.service.UploadAttachments(reports.Actor{
+		ID:   currentUserUUID,
+		Role: pkg.RoleType(cast.ToString(ctx.Locals("role"))),
+	}, reportID, form.File["files"])
+	if err != nil {
+		switch {
+		case errors.Is(err, reports.ErrReportNotFound):
+			return ctx.Status(fiber.StatusNotFound).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		case errors.Is(err, attachments.ErrForbidden):
+			return ctx.Status(fiber.StatusForbidden).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		case errors.Is(err, attachments.ErrUnsupportedType):
+			return ctx.Status(fiber.StatusUnsupportedMediaType).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		case errors.Is(err, attachments.ErrFileTooLarge):
+			return ctx.Status(fiber.StatusRequestEntityTooLarge).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		case errors.Is(err, attachments.ErrNoFiles),
+			errors.Is(err, attachments.ErrTooManyFiles),
+			errors.Is(err, attachments.ErrInvalidImage):
+			return ctx.Status(fiber.StatusBadRequest).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: result,
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
+
+func (c *AttachmentsController) GetAttachments(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	reportID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid report id",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	result, err := c.service.GetAttachments(currentUserUUID, cast.ToString(ctx.Locals("role")), reportID)
+	if err != nil {
+		if errors.Is(err, reports.ErrReportNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: result,
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
+
+func (c *AttachmentsController) DeleteAttachment(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid attachment id",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	err = c.service.DeleteAttachment(reports.Actor{
+		ID:   currentUserUUID,
+		Role: pkg.RoleType(cast.ToString(ctx.Locals("role"))),
+	}, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, attachments.ErrAttachmentNotFound), errors.Is(err, reports.ErrReportNotFound):
+			return ctx.Status(fiber.StatusNotFound).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		case errors.Is(err, attachments.ErrForbidden):
+			return ctx.Status(fiber.StatusForbidden).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: id.String(),
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
@@ -0,0 +1,454 @@
+package controllers
+
+import (
+	"errors"
+	"hubku/lapor_warga_be_v2/internal/modules/calendar"
+	"hubku/lapor_warga_be_v2/pkg"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/spf13/cast"
+)
+
+// maxCalendarFileSize caps the size of an imported iCalendar file.
+const maxCalendarFileSize = 1 << 20
+
+type CalendarsController struct {
+	service   calendar.CalendarService
+	validator *validator.Validate
+}
+
+func NewCalendarsController(s calendar.CalendarService, v *validator.Validate) *CalendarsController {
+	return &CalendarsController{service: s, validator: v}
+}
+
+func (c *CalendarsController) GetCalendar(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	var areaID pgtype.UUID
+	if value := ctx.Query("area_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(
+				pkg.ErrorResponse{
+					Error: "invalid area id",
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+		areaID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	result, err := c.service.GetCalendar(areaID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: result,
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
+
+func (c *CalendarsController) SetWorkingHours(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	var req calendar.WorkingHoursRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid json body",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	if err := pkg.ValidateInput(req, c.validator); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: err,
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	result, err := c.service.SetWorkingHours(currentUserUUID, req)
+	if err != nil {
+		if errors.Is(err, calendar.ErrInvalidTimezone) || errors.Is(err, calendar.ErrInvalidHours) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: result,
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
+
+func (c *CalendarsController) DeleteAreaCalendar(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	areaID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid area id",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	err = c.service.DeleteAreaCalendar(currentUserUUID, areaID)
+	if err != nil {
+		if errors.Is(err, calendar.ErrNoCalendar) {
+			return ctx.Status(fiber.StatusNotFound).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: areaID.String(),
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
+
+func (c *CalendarsController) AddHoliday(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	var req calendar.HolidayRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid json body",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	if err := pkg.ValidateInput(req, c.validator); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: err,
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	result, err := c.service.AddHoliday(currentUserUUID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, calendar.ErrNoCalendar):
+			return ctx.Status(fiber.StatusNotFound).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		case errors.Is(err, calendar.ErrInvalidDate):
+			return ctx.Status(fiber.StatusBadRequest).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: result,
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
+
+func (c *CalendarsController) DeleteHoliday(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid holiday id",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	err = c.service.DeleteHoliday(currentUserUUID, id)
+	if err != nil {
+		if errors.Is(err, calendar.ErrHolidayNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: id.String(),
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
+
+func (c *CalendarsController) ImportHolidays(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	areaID := ctx.FormValue("area_id")
+	if areaID != "" {
+		if _, err := uuid.Parse(areaID); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(
+				pkg.ErrorResponse{
+					Error: "invalid area id",
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid multipart form",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	if header.Size > maxCalendarFileSize {
+		return ctx.Status(fiber.StatusRequestEntityTooLarge).JSON(
+			pkg.ErrorResponse{
+				Error: "file too large",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+	defer file.Close()
+
+	result, err := c.service.ImportHolidays(currentUserUUID, areaID, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, calendar.ErrNoCalendar):
+			return ctx.Status(fiber.StatusNotFound).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		case errors.Is(err, calendar.ErrInvalidICS):
+			return ctx.Status(fiber.StatusBadRequest).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: result,
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
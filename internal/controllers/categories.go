@@ -395,6 +395,92 @@ func (c *CategoriesController) UpdateCategory(ctx *fiber.Ctx) error {
 	)
 }
 
+func (c *CategoriesController) UpdateCategorySLA(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	id := ctx.Params("id")
+	uuid, err := uuid.Parse(id)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid category id",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	var req categories.UpdateCategorySLARequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid json body",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	if err := pkg.ValidateInput(req, c.validator); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: err,
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	category, err := c.service.UpdateCategorySLA(currentUserUUID, uuid, req)
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return ctx.Status(fiber.StatusNotFound).JSON(
+				pkg.ErrorResponse{
+					Error: "category not found",
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: category,
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
+
 func (c *CategoriesController) DeleteCategory(ctx *fiber.Ctx) error {
 	startTime := time.Now()
 
@@ -0,0 +1,370 @@
+package controllers
+
+import (
+	"errors"
+	"hubku/lapor_warga_be_v2/internal/modules/comments"
+	"hubku/lapor_warga_be_v2/internal/modules/reports"
+	"hubku/lapor_warga_be_v2/pkg"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/cast"
+)
+
+type CommentsController struct {
+	service   comments.CommentsService
+	validator *validator.Validate
+}
+
+func NewCommentsController(s comments.CommentsService, v *validator.Validate) *CommentsController {
+	return &CommentsController{service: s, validator: v}
+}
+
+func (c *CommentsController) CreateComment(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	reportID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid report id",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	var req comments.CreateCommentRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid json body",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	if err := pkg.ValidateInput(req, c.validator); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: err,
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	result, err := c.service.CreateComment(reports.Actor{
+		ID:   currentUserUUID,
+		Role: pkg.RoleType(cast.ToString(ctx.Locals("role"))),
+	}, reportID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, reports.ErrReportNotFound), errors.Is(err, comments.ErrParentNotFound):
+			return ctx.Status(fiber.StatusNotFound).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		case errors.Is(err, comments.ErrMaxDepth):
+			return ctx.Status(fiber.StatusUnprocessableEntity).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: result,
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
+
+func (c *CommentsController) UpdateComment(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid comment id",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	var req comments.UpdateCommentRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid json body",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	if err := pkg.ValidateInput(req, c.validator); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: err,
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	result, err := c.service.UpdateComment(reports.Actor{
+		ID:   currentUserUUID,
+		Role: pkg.RoleType(cast.ToString(ctx.Locals("role"))),
+	}, id, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, comments.ErrCommentNotFound):
+			return ctx.Status(fiber.StatusNotFound).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		case errors.Is(err, comments.ErrForbidden):
+			return ctx.Status(fiber.StatusForbidden).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: result,
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
+
+func (c *CommentsController) DeleteComment(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid comment id",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	err = c.service.DeleteComment(reports.Actor{
+		ID:   currentUserUUID,
+		Role: pkg.RoleType(cast.ToString(ctx.Locals("role"))),
+	}, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, comments.ErrCommentNotFound):
+			return ctx.Status(fiber.StatusNotFound).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		case errors.Is(err, comments.ErrForbidden):
+			return ctx.Status(fiber.StatusForbidden).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: id.String(),
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
+
+func (c *CommentsController) GetComments(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	reportID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid report id",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	result, err := c.service.GetComments(
+		currentUserUUID,
+		cast.ToString(ctx.Locals("role")),
+		reportID,
+		ctx.Query("cursor"),
+		ctx.QueryInt("limit", 20),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, reports.ErrReportNotFound):
+			return ctx.Status(fiber.StatusNotFound).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		case errors.Is(err, pkg.ErrInvalidCursor):
+			return ctx.Status(fiber.StatusBadRequest).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: result,
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
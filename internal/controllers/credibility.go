@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"errors"
+	"hubku/lapor_warga_be_v2/internal/modules/credibility"
+	"hubku/lapor_warga_be_v2/pkg"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/cast"
+)
+
+type CredibilityController struct {
+	service   credibility.CredibilityService
+	validator *validator.Validate
+}
+
+func NewCredibilityController(s credibility.CredibilityService, v *validator.Validate) *CredibilityController {
+	return &CredibilityController{service: s, validator: v}
+}
+
+func (c *CredibilityController) GetMyLedger(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 20)
+
+	result, err := c.service.GetLedger(currentUserUUID, page, limit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: result,
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
+
+func (c *CredibilityController) GetUserLedger(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	userID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid user id",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 20)
+
+	result, err := c.service.GetLedger(userID, page, limit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: result,
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
+
+func (c *CredibilityController) AdjustScore(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	userID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid user id",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	var req credibility.AdjustScoreRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: "invalid json body",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	if err := pkg.ValidateInput(req, c.validator); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: err,
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	result, err := c.service.Adjust(currentUserUUID, userID, req)
+	if err != nil {
+		if errors.Is(err, credibility.ErrUserNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	return ctx.JSON(
+		pkg.SuccessResponse{
+			Data: result,
+			Meta: pkg.Meta{
+				Duration: time.Since(startTime).String(),
+			},
+		},
+	)
+}
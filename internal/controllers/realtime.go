@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hubku/lapor_warga_be_v2/internal/modules/realtime"
+	"hubku/lapor_warga_be_v2/pkg"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/cast"
+)
+
+// heartbeatInterval keeps idle streams alive through proxies that close
+// silent connections.
+const heartbeatInterval = 20 * time.Second
+
+type RealtimeController struct {
+	service realtime.RealtimeService
+}
+
+func NewRealtimeController(s realtime.RealtimeService) *RealtimeController {
+	return &RealtimeController{service: s}
+}
+
+// Stream sends the report events the user can see as server-sent events
+// until the client disconnects, or until the access token expires: the
+// stream then ends with a token_expired event, and the client reconnects
+// once it refreshed its token.
+func (c *RealtimeController) Stream(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	sub, err := c.service.Subscribe(currentUserUUID, cast.ToString(ctx.Locals("role")))
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	expiresAt, _ := ctx.Locals("token_expires_at").(time.Time)
+
+	ctx.Set("Content-Type", "text/event-stream")
+	ctx.Set("Cache-Control", "no-cache")
+	ctx.Set("Connection", "keep-alive")
+	ctx.Set("X-Accel-Buffering", "no")
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+
+		fmt.Fprint(w, "retry: 3000\n: connected\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		// a nil channel never fires, for tokens without an expiry
+		var expired <-chan time.Time
+		if !expiresAt.IsZero() {
+			timer := time.NewTimer(time.Until(expiresAt))
+			defer timer.Stop()
+			expired = timer.C
+		}
+
+		for {
+			select {
+			case event, ok := <-sub.Events():
+				if !ok {
+					return
+				}
+
+				data, err := json.Marshal(event)
+				if err != nil {
+					log.Printf("realtime: encode %s event: %v", event.Type, err)
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			case <-expired:
+				fmt.Fprint(w, "event: token_expired\ndata: {}\n\n")
+				w.Flush()
+				return
+			}
+
+			// a failed flush means the client went away
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
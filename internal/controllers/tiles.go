@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"hubku/lapor_warga_be_v2/internal/modules/tiles"
+	"hubku/lapor_warga_be_v2/pkg"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/cast"
+)
+
+type TilesController struct {
+	service tiles.TilesService
+}
+
+func NewTilesController(s tiles.TilesService) *TilesController {
+	return &TilesController{service: s}
+}
+
+func (c *TilesController) GetTile(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	z, errZ := ctx.ParamsInt("z")
+	x, errX := ctx.ParamsInt("x")
+	y, errY := ctx.ParamsInt("y")
+	if errZ != nil || errX != nil || errY != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(
+			pkg.ErrorResponse{
+				Error: tiles.ErrInvalidTile.Error(),
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	currentUserID := ctx.Locals("user_id")
+	currentUserUUID, err := uuid.Parse(cast.ToString(currentUserID))
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(
+			pkg.ErrorResponse{
+				Error: "unauthenticated",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	role := cast.ToString(ctx.Locals("role"))
+	layer := ctx.Params("layer")
+
+	tile, err := c.service.GetTile(currentUserUUID, role, layer, z, x, y)
+	if err != nil {
+		switch {
+		case errors.Is(err, tiles.ErrUnknownLayer):
+			return ctx.Status(fiber.StatusNotFound).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		case errors.Is(err, tiles.ErrInvalidTile):
+			return ctx.Status(fiber.StatusBadRequest).JSON(
+				pkg.ErrorResponse{
+					Error: err.Error(),
+					Meta: pkg.Meta{
+						Duration: time.Since(startTime).String(),
+					},
+				},
+			)
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).JSON(
+			pkg.ErrorResponse{
+				Error: "internal server error",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	sum := sha1.Sum(tile)
+
+	ctx.Set(fiber.HeaderContentType, "application/vnd.mapbox-vector-tile")
+	ctx.Set(fiber.HeaderCacheControl, c.service.CacheControl(role, layer))
+	ctx.Set(fiber.HeaderETag, `"`+hex.EncodeToString(sum[:])+`"`)
+
+	// the client already has this tile
+	if ctx.Fresh() {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	return ctx.Send(tile)
+}
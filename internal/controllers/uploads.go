@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"hubku/lapor_warga_be_v2/internal/modules/attachments"
+	"hubku/lapor_warga_be_v2/pkg"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type UploadsController struct {
+	storage *attachments.LocalStorage
+}
+
+func NewUploadsController(s *attachments.LocalStorage) *UploadsController {
+	return &UploadsController{storage: s}
+}
+
+// ServeFile serves a locally stored upload to holders of a signed URL, which
+// the attachments endpoints only hand out for reports the user can see.
+func (c *UploadsController) ServeFile(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+
+	path, err := c.storage.Open(ctx.Params("*"), ctx.Query("expires"), ctx.Query("signature"), time.Now())
+	if err != nil {
+		return ctx.Status(fiber.StatusForbidden).JSON(
+			pkg.ErrorResponse{
+				Error: err.Error(),
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(
+			pkg.ErrorResponse{
+				Error: "file not found",
+				Meta: pkg.Meta{
+					Duration: time.Since(startTime).String(),
+				},
+			},
+		)
+	}
+
+	// the URL stops working when it expires, shared caches must not keep it
+	expires, _ := strconv.ParseInt(ctx.Query("expires"), 10, 64)
+	ctx.Set("Cache-Control", "private, max-age="+strconv.FormatInt(expires-time.Now().Unix(), 10))
+
+	return ctx.SendFile(path)
+}
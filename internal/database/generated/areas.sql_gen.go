@@ -76,6 +76,42 @@ func (q *Queries) CreateArea(ctx context.Context, arg CreateAreaParams) (uuid.UU
 	return id, err
 }
 
+const findAreaByPoint = `-- name: FindAreaByPoint :one
+WITH RECURSIVE matched AS (
+    SELECT id, parent_id
+    FROM areas
+    WHERE is_active = TRUE
+      AND deleted_at IS NULL
+      AND ST_Covers(boundary, ST_SetSRID(ST_MakePoint($1::float8, $2::float8), 4326))
+), ancestry AS (
+    SELECT id AS area_id, parent_id, 0 AS depth
+    FROM matched
+    UNION ALL
+    SELECT an.area_id, p.parent_id, an.depth + 1
+    FROM ancestry an
+    JOIN areas p ON p.id = an.parent_id
+    WHERE an.depth < 10
+)
+SELECT area_id
+FROM ancestry
+GROUP BY area_id
+ORDER BY MAX(depth) DESC
+LIMIT 1
+`
+
+type FindAreaByPointParams struct {
+	Lng float64 `db:"lng" json:"lng"`
+	Lat float64 `db:"lat" json:"lat"`
+}
+
+// Returns the deepest active area (following parent_id) covering the point.
+func (q *Queries) FindAreaByPoint(ctx context.Context, arg FindAreaByPointParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, findAreaByPoint, arg.Lng, arg.Lat)
+	var area_id uuid.UUID
+	err := row.Scan(&area_id)
+	return area_id, err
+}
+
 const getAreaBoundary = `-- name: GetAreaBoundary :one
 SELECT
     id,
@@ -95,6 +95,8 @@ SELECT
     color,
     is_active,
     sort_order,
+    response_sla_minutes,
+    resolution_sla_minutes,
     created_at,
     updated_at
 FROM categories
@@ -103,15 +105,17 @@ ORDER BY sort_order ASC
 `
 
 type GetCategoriesRow struct {
-	ID        uuid.UUID          `db:"id" json:"id"`
-	Name      string             `db:"name" json:"name"`
-	Slug      string             `db:"slug" json:"slug"`
-	Icon      pgtype.Text        `db:"icon" json:"icon"`
-	Color     pgtype.Text        `db:"color" json:"color"`
-	IsActive  pgtype.Bool        `db:"is_active" json:"is_active"`
-	SortOrder pgtype.Int4        `db:"sort_order" json:"sort_order"`
-	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	ID                   uuid.UUID          `db:"id" json:"id"`
+	Name                 string             `db:"name" json:"name"`
+	Slug                 string             `db:"slug" json:"slug"`
+	Icon                 pgtype.Text        `db:"icon" json:"icon"`
+	Color                pgtype.Text        `db:"color" json:"color"`
+	IsActive             pgtype.Bool        `db:"is_active" json:"is_active"`
+	SortOrder            pgtype.Int4        `db:"sort_order" json:"sort_order"`
+	ResponseSlaMinutes   pgtype.Int4        `db:"response_sla_minutes" json:"response_sla_minutes"`
+	ResolutionSlaMinutes pgtype.Int4        `db:"resolution_sla_minutes" json:"resolution_sla_minutes"`
+	CreatedAt            pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt            pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
 }
 
 func (q *Queries) GetCategories(ctx context.Context) ([]GetCategoriesRow, error) {
@@ -131,6 +135,8 @@ func (q *Queries) GetCategories(ctx context.Context) ([]GetCategoriesRow, error)
 			&i.Color,
 			&i.IsActive,
 			&i.SortOrder,
+			&i.ResponseSlaMinutes,
+			&i.ResolutionSlaMinutes,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -153,6 +159,8 @@ SELECT
     color,
     is_active,
     sort_order,
+    response_sla_minutes,
+    resolution_sla_minutes,
     created_at,
     updated_at
 FROM categories
@@ -160,15 +168,17 @@ WHERE id = $1 AND deleted_at IS NULL
 `
 
 type GetCategoryByIdRow struct {
-	ID        uuid.UUID          `db:"id" json:"id"`
-	Name      string             `db:"name" json:"name"`
-	Slug      string             `db:"slug" json:"slug"`
-	Icon      pgtype.Text        `db:"icon" json:"icon"`
-	Color     pgtype.Text        `db:"color" json:"color"`
-	IsActive  pgtype.Bool        `db:"is_active" json:"is_active"`
-	SortOrder pgtype.Int4        `db:"sort_order" json:"sort_order"`
-	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	ID                   uuid.UUID          `db:"id" json:"id"`
+	Name                 string             `db:"name" json:"name"`
+	Slug                 string             `db:"slug" json:"slug"`
+	Icon                 pgtype.Text        `db:"icon" json:"icon"`
+	Color                pgtype.Text        `db:"color" json:"color"`
+	IsActive             pgtype.Bool        `db:"is_active" json:"is_active"`
+	SortOrder            pgtype.Int4        `db:"sort_order" json:"sort_order"`
+	ResponseSlaMinutes   pgtype.Int4        `db:"response_sla_minutes" json:"response_sla_minutes"`
+	ResolutionSlaMinutes pgtype.Int4        `db:"resolution_sla_minutes" json:"resolution_sla_minutes"`
+	CreatedAt            pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt            pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
 }
 
 func (q *Queries) GetCategoryById(ctx context.Context, id uuid.UUID) (GetCategoryByIdRow, error) {
@@ -182,6 +192,8 @@ func (q *Queries) GetCategoryById(ctx context.Context, id uuid.UUID) (GetCategor
 		&i.Color,
 		&i.IsActive,
 		&i.SortOrder,
+		&i.ResponseSlaMinutes,
+		&i.ResolutionSlaMinutes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -382,3 +394,32 @@ func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams)
 	err := row.Scan(&id)
 	return id, err
 }
+
+const updateCategorySLA = `-- name: UpdateCategorySLA :one
+UPDATE categories
+SET
+    response_sla_minutes = $1,
+    resolution_sla_minutes = $2
+WHERE id = $3 AND deleted_at IS NULL
+RETURNING id, response_sla_minutes, resolution_sla_minutes
+`
+
+type UpdateCategorySLAParams struct {
+	ResponseSlaMinutes   pgtype.Int4 `db:"response_sla_minutes" json:"response_sla_minutes"`
+	ResolutionSlaMinutes pgtype.Int4 `db:"resolution_sla_minutes" json:"resolution_sla_minutes"`
+	ID                   uuid.UUID   `db:"id" json:"id"`
+}
+
+type UpdateCategorySLARow struct {
+	ID                   uuid.UUID   `db:"id" json:"id"`
+	ResponseSlaMinutes   pgtype.Int4 `db:"response_sla_minutes" json:"response_sla_minutes"`
+	ResolutionSlaMinutes pgtype.Int4 `db:"resolution_sla_minutes" json:"resolution_sla_minutes"`
+}
+
+// NULL removes the deadline.
+func (q *Queries) UpdateCategorySLA(ctx context.Context, arg UpdateCategorySLAParams) (UpdateCategorySLARow, error) {
+	row := q.db.QueryRow(ctx, updateCategorySLA, arg.ResponseSlaMinutes, arg.ResolutionSlaMinutes, arg.ID)
+	var i UpdateCategorySLARow
+	err := row.Scan(&i.ID, &i.ResponseSlaMinutes, &i.ResolutionSlaMinutes)
+	return i, err
+}
@@ -5,6 +5,9 @@
 package db
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -34,36 +37,121 @@ type AuditLog struct {
 	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
 
+type BusinessCalendar struct {
+	ID        uuid.UUID          `db:"id" json:"id"`
+	AreaID    pgtype.UUID        `db:"area_id" json:"area_id"`
+	Timezone  string             `db:"timezone" json:"timezone"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type CalendarHoliday struct {
+	ID          uuid.UUID          `db:"id" json:"id"`
+	CalendarID  uuid.UUID          `db:"calendar_id" json:"calendar_id"`
+	HolidayDate pgtype.Date        `db:"holiday_date" json:"holiday_date"`
+	Name        string             `db:"name" json:"name"`
+	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type CalendarWorkingHour struct {
+	CalendarID uuid.UUID   `db:"calendar_id" json:"calendar_id"`
+	Weekday    int16       `db:"weekday" json:"weekday"`
+	StartTime  pgtype.Time `db:"start_time" json:"start_time"`
+	EndTime    pgtype.Time `db:"end_time" json:"end_time"`
+}
+
 type Category struct {
+	ID                   uuid.UUID          `db:"id" json:"id"`
+	Name                 string             `db:"name" json:"name"`
+	Slug                 string             `db:"slug" json:"slug"`
+	Icon                 pgtype.Text        `db:"icon" json:"icon"`
+	Color                pgtype.Text        `db:"color" json:"color"`
+	IsActive             pgtype.Bool        `db:"is_active" json:"is_active"`
+	SortOrder            pgtype.Int4        `db:"sort_order" json:"sort_order"`
+	CreatedAt            pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt            pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	DeletedAt            pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
+	ResponseSlaMinutes   pgtype.Int4        `db:"response_sla_minutes" json:"response_sla_minutes"`
+	ResolutionSlaMinutes pgtype.Int4        `db:"resolution_sla_minutes" json:"resolution_sla_minutes"`
+}
+
+type CredibilityLedger struct {
+	ID           uuid.UUID          `db:"id" json:"id"`
+	UserID       uuid.UUID          `db:"user_id" json:"user_id"`
+	Event        string             `db:"event" json:"event"`
+	Delta        int16              `db:"delta" json:"delta"`
+	ScoreBefore  int16              `db:"score_before" json:"score_before"`
+	ScoreAfter   int16              `db:"score_after" json:"score_after"`
+	StatusBefore string             `db:"status_before" json:"status_before"`
+	StatusAfter  string             `db:"status_after" json:"status_after"`
+	ReportID     pgtype.UUID        `db:"report_id" json:"report_id"`
+	ActorID      pgtype.UUID        `db:"actor_id" json:"actor_id"`
+	Reason       pgtype.Text        `db:"reason" json:"reason"`
+	CreatedAt    pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type DeviceToken struct {
 	ID        uuid.UUID          `db:"id" json:"id"`
-	Name      string             `db:"name" json:"name"`
-	Slug      string             `db:"slug" json:"slug"`
-	Icon      pgtype.Text        `db:"icon" json:"icon"`
-	Color     pgtype.Text        `db:"color" json:"color"`
-	IsActive  pgtype.Bool        `db:"is_active" json:"is_active"`
-	SortOrder pgtype.Int4        `db:"sort_order" json:"sort_order"`
+	UserID    uuid.UUID          `db:"user_id" json:"user_id"`
+	Token     string             `db:"token" json:"token"`
+	Platform  string             `db:"platform" json:"platform"`
+	Locale    string             `db:"locale" json:"locale"`
 	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
 	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
-	DeletedAt pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
 }
 
-type Report struct {
+type EmailOutbox struct {
 	ID            uuid.UUID          `db:"id" json:"id"`
-	Title         string             `db:"title" json:"title"`
-	Description   string             `db:"description" json:"description"`
-	Address       pgtype.Text        `db:"address" json:"address"`
-	Location      interface{}        `db:"location" json:"location"`
-	AreaID        pgtype.UUID        `db:"area_id" json:"area_id"`
-	CategoryID    uuid.UUID          `db:"category_id" json:"category_id"`
-	UserID        uuid.UUID          `db:"user_id" json:"user_id"`
+	RecipientEnc  []byte             `db:"recipient_enc" json:"recipient_enc"`
+	Template      string             `db:"template" json:"template"`
+	Locale        string             `db:"locale" json:"locale"`
+	Subject       string             `db:"subject" json:"subject"`
+	TextBodyEnc   []byte             `db:"text_body_enc" json:"text_body_enc"`
+	HtmlBodyEnc   []byte             `db:"html_body_enc" json:"html_body_enc"`
 	Status        string             `db:"status" json:"status"`
-	ViewCount     pgtype.Int8        `db:"view_count" json:"view_count"`
-	UpvoteCount   pgtype.Int8        `db:"upvote_count" json:"upvote_count"`
-	DownvoteCount pgtype.Int8        `db:"downvote_count" json:"downvote_count"`
-	ResolvedAt    pgtype.Timestamptz `db:"resolved_at" json:"resolved_at"`
+	Attempts      int32              `db:"attempts" json:"attempts"`
+	LastError     pgtype.Text        `db:"last_error" json:"last_error"`
+	NextAttemptAt time.Time          `db:"next_attempt_at" json:"next_attempt_at"`
+	SentAt        pgtype.Timestamptz `db:"sent_at" json:"sent_at"`
 	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
-	UpdatedAt     pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
-	DeletedAt     pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
+}
+
+type Notification struct {
+	ID        uuid.UUID          `db:"id" json:"id"`
+	UserID    uuid.UUID          `db:"user_id" json:"user_id"`
+	Type      string             `db:"type" json:"type"`
+	ReportID  pgtype.UUID        `db:"report_id" json:"report_id"`
+	ActorID   pgtype.UUID        `db:"actor_id" json:"actor_id"`
+	Data      json.RawMessage    `db:"data" json:"data"`
+	ReadAt    pgtype.Timestamptz `db:"read_at" json:"read_at"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type Report struct {
+	ID                     uuid.UUID          `db:"id" json:"id"`
+	Title                  string             `db:"title" json:"title"`
+	Description            string             `db:"description" json:"description"`
+	Address                pgtype.Text        `db:"address" json:"address"`
+	Location               interface{}        `db:"location" json:"location"`
+	AreaID                 pgtype.UUID        `db:"area_id" json:"area_id"`
+	CategoryID             uuid.UUID          `db:"category_id" json:"category_id"`
+	UserID                 uuid.UUID          `db:"user_id" json:"user_id"`
+	Status                 string             `db:"status" json:"status"`
+	ViewCount              pgtype.Int8        `db:"view_count" json:"view_count"`
+	UpvoteCount            pgtype.Int8        `db:"upvote_count" json:"upvote_count"`
+	DownvoteCount          pgtype.Int8        `db:"downvote_count" json:"downvote_count"`
+	ResolvedAt             pgtype.Timestamptz `db:"resolved_at" json:"resolved_at"`
+	CreatedAt              pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt              pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	DeletedAt              pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
+	LocationMismatch       pgtype.Bool        `db:"location_mismatch" json:"location_mismatch"`
+	LocationMismatchReason pgtype.Text        `db:"location_mismatch_reason" json:"location_mismatch_reason"`
+	SpamReviewedAt         pgtype.Timestamptz `db:"spam_reviewed_at" json:"spam_reviewed_at"`
+	SpamDecision           pgtype.Text        `db:"spam_decision" json:"spam_decision"`
+	MergedIntoID           pgtype.UUID        `db:"merged_into_id" json:"merged_into_id"`
+	MergedAt               pgtype.Timestamptz `db:"merged_at" json:"merged_at"`
+	AssignedTo             pgtype.UUID        `db:"assigned_to" json:"assigned_to"`
+	AssignedAt             pgtype.Timestamptz `db:"assigned_at" json:"assigned_at"`
 }
 
 type ReportAttachment struct {
@@ -76,6 +164,16 @@ type ReportAttachment struct {
 	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
 
+type ReportAttachmentThumbnail struct {
+	ID           uuid.UUID          `db:"id" json:"id"`
+	AttachmentID uuid.UUID          `db:"attachment_id" json:"attachment_id"`
+	Size         string             `db:"size" json:"size"`
+	Width        int32              `db:"width" json:"width"`
+	Height       int32              `db:"height" json:"height"`
+	FileUrl      string             `db:"file_url" json:"file_url"`
+	CreatedAt    pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
 type ReportComment struct {
 	ID        uuid.UUID          `db:"id" json:"id"`
 	ReportID  uuid.UUID          `db:"report_id" json:"report_id"`
@@ -87,6 +185,23 @@ type ReportComment struct {
 	DeletedAt pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
 }
 
+type ReportEscalation struct {
+	ID        uuid.UUID          `db:"id" json:"id"`
+	ReportID  uuid.UUID          `db:"report_id" json:"report_id"`
+	Kind      string             `db:"kind" json:"kind"`
+	AreaID    pgtype.UUID        `db:"area_id" json:"area_id"`
+	Deadline  time.Time          `db:"deadline" json:"deadline"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type ReportSlaDeadline struct {
+	ReportID   uuid.UUID          `db:"report_id" json:"report_id"`
+	Kind       string             `db:"kind" json:"kind"`
+	SlaMinutes int32              `db:"sla_minutes" json:"sla_minutes"`
+	DueAt      time.Time          `db:"due_at" json:"due_at"`
+	ComputedAt pgtype.Timestamptz `db:"computed_at" json:"computed_at"`
+}
+
 type ReportSpamFlag struct {
 	ID        uuid.UUID          `db:"id" json:"id"`
 	ReportID  uuid.UUID          `db:"report_id" json:"report_id"`
@@ -120,6 +235,14 @@ type ReportVote struct {
 	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
 
+type ReportVoteChange struct {
+	ID            uuid.UUID `db:"id" json:"id"`
+	ReportID      uuid.UUID `db:"report_id" json:"report_id"`
+	UpvoteDelta   int32     `db:"upvote_delta" json:"upvote_delta"`
+	DownvoteDelta int32     `db:"downvote_delta" json:"downvote_delta"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
 type Role struct {
 	ID            uuid.UUID          `db:"id" json:"id"`
 	Name          string             `db:"name" json:"name"`
@@ -158,3 +281,10 @@ type User struct {
 	DeletedAt           pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
 	DeletedBy           pgtype.UUID        `db:"deleted_by" json:"deleted_by"`
 }
+
+type UserArea struct {
+	UserID     uuid.UUID          `db:"user_id" json:"user_id"`
+	AreaID     uuid.UUID          `db:"area_id" json:"area_id"`
+	AssignedBy pgtype.UUID        `db:"assigned_by" json:"assigned_by"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
@@ -8,50 +8,224 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
 	AssignRoleToUser(ctx context.Context, arg AssignRoleToUserParams) error
+	// No row is returned when the area does not exist. Assigning an area twice only updates assigned_by.
+	AssignUserArea(ctx context.Context, arg AssignUserAreaParams) (UserArea, error)
 	CheckAreaExist(ctx context.Context, arg CheckAreaExistParams) (uuid.UUID, error)
 	CheckCategoryExist(ctx context.Context, arg CheckCategoryExistParams) (bool, error)
 	CheckRoleExists(ctx context.Context, name string) (bool, error)
 	CheckUserExists(ctx context.Context, arg CheckUserExistsParams) (bool, error)
+	// Leases due emails until lease_until, so concurrent workers do not send
+	// the same email twice. An email whose worker died is picked up again once
+	// the lease runs out.
+	ClaimDueEmails(ctx context.Context, arg ClaimDueEmailsParams) ([]EmailOutbox, error)
+	// Forgets every computed deadline, for when business calendars change.
+	ClearSLADeadlines(ctx context.Context) error
+	CountAssignedReportsByStatus(ctx context.Context, assignedTo pgtype.UUID) ([]CountAssignedReportsByStatusRow, error)
+	CountReportAttachments(ctx context.Context, reportID uuid.UUID) (int64, error)
+	CountUnreadNotifications(ctx context.Context, userID uuid.UUID) (int64, error)
 	CreateArea(ctx context.Context, arg CreateAreaParams) (uuid.UUID, error)
 	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) error
+	CreateBusinessCalendar(ctx context.Context, arg CreateBusinessCalendarParams) (uuid.UUID, error)
 	CreateCategory(ctx context.Context, arg CreateCategoryParams) (uuid.UUID, error)
+	// Returns no row when the event was already scored.
+	CreateCredibilityEntry(ctx context.Context, arg CreateCredibilityEntryParams) (CredibilityLedger, error)
+	// One notification per user in user_ids.
+	CreateNotifications(ctx context.Context, arg CreateNotificationsParams) ([]Notification, error)
+	CreateOutboxEmail(ctx context.Context, arg CreateOutboxEmailParams) (uuid.UUID, error)
+	CreateReport(ctx context.Context, arg CreateReportParams) (uuid.UUID, error)
+	CreateReportAttachment(ctx context.Context, arg CreateReportAttachmentParams) (ReportAttachment, error)
+	CreateReportAttachmentThumbnail(ctx context.Context, arg CreateReportAttachmentThumbnailParams) (ReportAttachmentThumbnail, error)
+	CreateReportComment(ctx context.Context, arg CreateReportCommentParams) (ReportComment, error)
+	// Returns no rows when the report was already escalated for this kind.
+	CreateReportEscalation(ctx context.Context, arg CreateReportEscalationParams) (uuid.UUID, error)
+	// Returns no row when the user already flagged the report.
+	CreateReportSpamFlag(ctx context.Context, arg CreateReportSpamFlagParams) (ReportSpamFlag, error)
+	CreateReportStatusHistory(ctx context.Context, arg CreateReportStatusHistoryParams) (ReportStatusHistory, error)
+	CreateReportVote(ctx context.Context, arg CreateReportVoteParams) (ReportVote, error)
 	CreateRole(ctx context.Context, arg CreateRoleParams) (uuid.UUID, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (uuid.UUID, error)
+	CreateWorkingHours(ctx context.Context, arg CreateWorkingHoursParams) error
+	DeleteAreaCalendar(ctx context.Context, areaID uuid.UUID) (uuid.UUID, error)
 	DeleteCategory(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	DeleteDeviceToken(ctx context.Context, arg DeleteDeviceTokenParams) (int64, error)
+	// Prunes tokens the push provider no longer accepts.
+	DeleteDeviceTokens(ctx context.Context, tokens []string) (int64, error)
+	DeleteHoliday(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	DeleteReportAttachment(ctx context.Context, id uuid.UUID) error
+	DeleteReportVote(ctx context.Context, arg DeleteReportVoteParams) error
 	DeleteRole(ctx context.Context, id uuid.UUID) error
 	DeleteUser(ctx context.Context, arg DeleteUserParams) error
+	DeleteWorkingHours(ctx context.Context, calendarID uuid.UUID) error
+	// Returns the deepest active area (following parent_id) covering the point.
+	FindAreaByPoint(ctx context.Context, arg FindAreaByPointParams) (uuid.UUID, error)
+	// Open or under-review reports of the same category near a point, created since created_from,
+	// whose title or description is at least min_similarity alike (pg_trgm), most similar first.
+	FindDuplicateReports(ctx context.Context, arg FindDuplicateReportsParams) ([]FindDuplicateReportsRow, error)
+	FlagReportLocationMismatch(ctx context.Context, arg FlagReportLocationMismatchParams) error
+	// Stores buffered views, sessions already seen are ignored, and adds the newly stored views to the report counters.
+	FlushReportViews(ctx context.Context, arg FlushReportViewsParams) error
 	GetAreaBoundary(ctx context.Context, id uuid.UUID) (GetAreaBoundaryRow, error)
+	// The calendar of area_id or of its nearest ancestor that has one, falling
+	// back to the default calendar.
+	GetAreaCalendar(ctx context.Context, areaID pgtype.UUID) (GetAreaCalendarRow, error)
+	// Areas intersecting tile z/x/y, encoded as the "areas" layer of a Mapbox Vector Tile.
+	GetAreaTile(ctx context.Context, arg GetAreaTileParams) ([]byte, error)
 	GetAreas(ctx context.Context, arg GetAreasParams) ([]GetAreasRow, error)
+	GetAssignedReports(ctx context.Context, arg GetAssignedReportsParams) ([]GetAssignedReportsRow, error)
 	GetAuditLogs(ctx context.Context) ([]AuditLog, error)
+	// The calendar set on area_id itself, the default calendar when area_id is NULL.
+	GetCalendarByArea(ctx context.Context, areaID pgtype.UUID) (GetCalendarByAreaRow, error)
+	GetCalendarHolidays(ctx context.Context, calendarIds []uuid.UUID) ([]GetCalendarHolidaysRow, error)
 	GetCategories(ctx context.Context) ([]GetCategoriesRow, error)
 	GetCategoryById(ctx context.Context, id uuid.UUID) (GetCategoryByIdRow, error)
 	GetCategoryBySlug(ctx context.Context, slug string) (GetCategoryBySlugRow, error)
+	// Newest first.
+	GetCredibilityLedger(ctx context.Context, arg GetCredibilityLedgerParams) ([]GetCredibilityLedgerRow, error)
+	GetDefaultCalendar(ctx context.Context) (GetDefaultCalendarRow, error)
+	GetDeviceTokensByUsers(ctx context.Context, userIds []uuid.UUID) ([]GetDeviceTokensByUsersRow, error)
+	// Officials whose jurisdiction covers area_id, the admins when area_id is NULL.
+	GetEscalationRecipients(ctx context.Context, areaID pgtype.UUID) ([]uuid.UUID, error)
+	GetEscalations(ctx context.Context, arg GetEscalationsParams) ([]GetEscalationsRow, error)
+	// Reports inside a bounding box, optionally also within radius_meters of the caller, closest first.
+	// The && check on the envelope is served by the GIST index on location, the exact
+	// geography distance is only computed for the rows it lets through.
+	GetNearbyReports(ctx context.Context, arg GetNearbyReportsParams) ([]GetNearbyReportsRow, error)
+	// The official covering area_id (through it or one of its ancestors) who was least recently
+	// assigned a report, round-robin style. With user_id set, only that official can be returned.
+	GetNextAssignee(ctx context.Context, arg GetNextAssigneeParams) (GetNextAssigneeRow, error)
+	// Newest first, paginated by (created_at, id) cursor.
+	GetNotifications(ctx context.Context, arg GetNotificationsParams) ([]GetNotificationsRow, error)
+	GetOutboxEmails(ctx context.Context, arg GetOutboxEmailsParams) ([]GetOutboxEmailsRow, error)
+	GetReportAttachmentByID(ctx context.Context, id uuid.UUID) (ReportAttachment, error)
+	GetReportAttachmentThumbnails(ctx context.Context, attachmentIds []uuid.UUID) ([]ReportAttachmentThumbnail, error)
+	GetReportAttachments(ctx context.Context, reportID uuid.UUID) ([]ReportAttachment, error)
+	GetReportByID(ctx context.Context, arg GetReportByIDParams) (GetReportByIDRow, error)
+	// Groups the reports inside a bounding box into square web mercator cells of cell_size meters,
+	// one row per non-empty cell with its count, centroid, extent and per-category counts.
+	GetReportClusters(ctx context.Context, arg GetReportClustersParams) ([]GetReportClustersRow, error)
+	GetReportCommentByID(ctx context.Context, id uuid.UUID) (ReportComment, error)
+	// Depth of a comment in its thread, top-level comments have depth 1.
+	GetReportCommentDepth(ctx context.Context, id uuid.UUID) (int32, error)
+	// All replies below the given comments, down to max_depth levels.
+	GetReportCommentReplies(ctx context.Context, arg GetReportCommentRepliesParams) ([]GetReportCommentRepliesRow, error)
+	// One page of the report feed. Every sort mode is reduced to a single rank_score, highest first:
+	// hot decays (net votes + 1) weighted by the reporter's credibility with the report's age at as_of,
+	// top is the net votes, new the creation time and nearby the negated distance to the caller.
+	// Votes and credibility are taken as they were at as_of: vote counter changes logged after it are
+	// taken back, and the credibility is the score before the reporter's first ledger entry after it.
+	// Reports created after as_of are left out, so every page ranks the same reports the same way.
+	GetReportFeed(ctx context.Context, arg GetReportFeedParams) ([]GetReportFeedRow, error)
+	GetReportForUpdate(ctx context.Context, id uuid.UUID) (GetReportForUpdateRow, error)
+	// Sums what the events already cost the user for the report.
+	GetReportPenalty(ctx context.Context, arg GetReportPenaltyParams) (int32, error)
+	// What the SLA of a report is measured against: when it was first responded
+	// to (its status changed by someone) and when it was first resolved or hidden.
+	GetReportSLA(ctx context.Context, id uuid.UUID) (GetReportSLARow, error)
+	// Sum of the flaggers' credibility scores over the flags not reviewed by a moderator yet.
+	GetReportSpamWeight(ctx context.Context, reportID uuid.UUID) (float64, error)
+	GetReportStatusHistory(ctx context.Context, reportID uuid.UUID) ([]GetReportStatusHistoryRow, error)
+	// Reports inside tile z/x/y, encoded as the "reports" layer of a Mapbox Vector Tile.
+	GetReportTile(ctx context.Context, arg GetReportTileParams) ([]byte, error)
+	GetReportVote(ctx context.Context, arg GetReportVoteParams) (ReportVote, error)
+	GetReports(ctx context.Context, arg GetReportsParams) ([]GetReportsRow, error)
+	GetReportsByUser(ctx context.Context, arg GetReportsByUserParams) ([]GetReportsByUserRow, error)
 	GetRoleByID(ctx context.Context, id uuid.UUID) (Role, error)
 	GetRoleByName(ctx context.Context, name string) (Role, error)
+	// Under review and open reports past the response or resolution SLA of their
+	// category in wall-clock time, which were not escalated for it yet. Business
+	// time never runs faster than wall-clock time, so every breach is among them.
+	// A report counts as responded to once someone changed its status.
+	// Reports whose business time deadline was computed earlier and is still
+	// ahead are left out until it passes.
+	// escalate_to is the parent of the report's area, NULL when there is none.
+	// Rows come in (deadline, report_id, kind) order after the given cursor.
+	GetSLACandidates(ctx context.Context, arg GetSLACandidatesParams) ([]GetSLACandidatesRow, error)
+	// Reports with flags waiting for a moderator, heaviest first.
+	GetSpamQueue(ctx context.Context, arg GetSpamQueueParams) ([]GetSpamQueueRow, error)
+	// The status a report had before it was last hidden.
+	GetStatusBeforeHidden(ctx context.Context, reportID uuid.UUID) (string, error)
+	// Newest first, paginated by (created_at, id) cursor. Deleted comments are
+	// only kept when a reply below them is not deleted.
+	GetTopLevelReportComments(ctx context.Context, arg GetTopLevelReportCommentsParams) ([]GetTopLevelReportCommentsRow, error)
+	// The areas assigned to a user together with all of their descendants.
+	GetUserAreaTree(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	GetUserAreas(ctx context.Context, userID uuid.UUID) ([]GetUserAreasRow, error)
 	GetUserByEmail(ctx context.Context, emailHash string) (GetUserByEmailRow, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (GetUserByIDRow, error)
 	GetUserByIdentifier(ctx context.Context, arg GetUserByIdentifierParams) (GetUserByIdentifierRow, error)
+	// Locks the user row, so concurrent events are applied one after another.
+	GetUserCredibilityForUpdate(ctx context.Context, id uuid.UUID) (GetUserCredibilityForUpdateRow, error)
 	GetUsers(ctx context.Context, arg GetUsersParams) ([]GetUsersRow, error)
 	GetUsersByRoleName(ctx context.Context, roleName string) ([]GetUsersByRoleNameRow, error)
+	GetWorkingHours(ctx context.Context, calendarID uuid.UUID) ([]GetWorkingHoursRow, error)
 	HasRole(ctx context.Context, arg HasRoleParams) (bool, error)
 	IncrementFailedLoginCount(ctx context.Context, id uuid.UUID) error
 	ListAllRoles(ctx context.Context) ([]Role, error)
+	// Serializes picking an assignee until the transaction ends. Officials can cover several areas
+	// through their ancestors, so a lock per area would still let two reports pick the same official.
+	LockAssignments(ctx context.Context) error
 	LockUser(ctx context.Context, arg LockUserParams) error
+	MarkAllNotificationsRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	// Schedules another attempt, or gives up once max_attempts is reached.
+	MarkEmailFailed(ctx context.Context, arg MarkEmailFailedParams) error
+	MarkEmailSent(ctx context.Context, id uuid.UUID) error
+	// No row is returned when the notification is not the user's.
+	MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (uuid.UUID, error)
+	MarkReportMerged(ctx context.Context, arg MarkReportMergedParams) error
+	// Closes the pending flags of a report, later flags start a new review.
+	MarkReportSpamReviewed(ctx context.Context, arg MarkReportSpamReviewedParams) error
+	MoveReportAttachments(ctx context.Context, arg MoveReportAttachmentsParams) error
+	// Moves the comments of merged reports onto the target, threads stay intact.
+	MoveReportComments(ctx context.Context, arg MoveReportCommentsParams) error
+	// Moves the views of merged reports onto the target, a session that saw several of them counts once.
+	MoveReportViews(ctx context.Context, arg MoveReportViewsParams) error
+	// Moves the votes of merged reports onto the target. A user keeps at most one vote on the target:
+	// an existing vote there wins, otherwise their latest vote on a source. Votes by the target's own
+	// reporter are dropped.
+	MoveReportVotes(ctx context.Context, arg MoveReportVotesParams) error
+	// Relays an event to the other instances listening on channel.
+	NotifyRealtimeEvent(ctx context.Context, arg NotifyRealtimeEventParams) error
+	// Recomputes the denormalized view and vote counters from report_views and report_votes, and logs
+	// how the vote counters moved to report_vote_changes.
+	RecountReportCounters(ctx context.Context, id uuid.UUID) (RecountReportCountersRow, error)
 	RemoveUserRole(ctx context.Context, userID uuid.UUID) error
+	// Reports merged earlier into one of the sources now point at the new target.
+	RepointMergedReports(ctx context.Context, arg RepointMergedReportsParams) error
 	ResetFailedLoginCount(ctx context.Context, id uuid.UUID) error
 	RestoreUser(ctx context.Context, id uuid.UUID) error
+	// Only failed emails can be retried, with a fresh number of attempts.
+	RetryOutboxEmail(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
 	SearchCategories(ctx context.Context, arg SearchCategoriesParams) ([]SearchCategoriesRow, error)
 	SearchUser(ctx context.Context, arg SearchUserParams) ([]SearchUserRow, error)
+	SetSLADeadline(ctx context.Context, arg SetSLADeadlineParams) error
+	SoftDeleteReportComment(ctx context.Context, id uuid.UUID) error
 	ToggleAreaActiveStatus(ctx context.Context, id uuid.UUID) (ToggleAreaActiveStatusRow, error)
 	ToggleCategoryActiveStatus(ctx context.Context, id uuid.UUID) (ToggleCategoryActiveStatusRow, error)
+	UnassignUserArea(ctx context.Context, arg UnassignUserAreaParams) (uuid.UUID, error)
+	UpdateCalendarTimezone(ctx context.Context, arg UpdateCalendarTimezoneParams) error
 	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (uuid.UUID, error)
+	// NULL removes the deadline.
+	UpdateCategorySLA(ctx context.Context, arg UpdateCategorySLAParams) (UpdateCategorySLARow, error)
 	UpdateLastLogin(ctx context.Context, id uuid.UUID) error
+	UpdateReportAssignee(ctx context.Context, arg UpdateReportAssigneeParams) error
+	UpdateReportAttachmentBlurhash(ctx context.Context, arg UpdateReportAttachmentBlurhashParams) error
+	UpdateReportComment(ctx context.Context, arg UpdateReportCommentParams) (ReportComment, error)
+	UpdateReportStatus(ctx context.Context, arg UpdateReportStatusParams) error
+	// Applies vote deltas to the denormalized counters and logs them to report_vote_changes, must run in
+	// the same transaction as the vote change.
+	UpdateReportVoteCounts(ctx context.Context, arg UpdateReportVoteCountsParams) (UpdateReportVoteCountsRow, error)
+	UpdateReportVoteType(ctx context.Context, arg UpdateReportVoteTypeParams) error
 	UpdateRole(ctx context.Context, arg UpdateRoleParams) error
 	UpdateUser(ctx context.Context, arg UpdateUserParams) error
+	UpdateUserCredibility(ctx context.Context, arg UpdateUserCredibilityParams) error
+	// Registering a token again moves it to the current user.
+	UpsertDeviceToken(ctx context.Context, arg UpsertDeviceTokenParams) (DeviceToken, error)
+	UpsertHoliday(ctx context.Context, arg UpsertHolidayParams) (CalendarHoliday, error)
 }
 
 var _ Querier = (*Queries)(nil)
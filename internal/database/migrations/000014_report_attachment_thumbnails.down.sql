@@ -0,0 +1 @@
+DROP TABLE IF EXISTS report_attachment_thumbnails;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS report_attachment_thumbnails (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    attachment_id UUID NOT NULL REFERENCES report_attachments(id) ON DELETE CASCADE,
+    size VARCHAR(20) NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    file_url TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE (attachment_id, size)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_attachment_thumbnails_attachment_id ON report_attachment_thumbnails(attachment_id);
\ No newline at end of file
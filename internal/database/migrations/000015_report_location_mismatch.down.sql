@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_reports_location_mismatch;
+
+ALTER TABLE reports
+    DROP COLUMN IF EXISTS location_mismatch_reason,
+    DROP COLUMN IF EXISTS location_mismatch;
\ No newline at end of file
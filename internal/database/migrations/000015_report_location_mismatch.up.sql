@@ -0,0 +1,5 @@
+ALTER TABLE reports
+    ADD COLUMN IF NOT EXISTS location_mismatch BOOLEAN DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS location_mismatch_reason TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_reports_location_mismatch ON reports(location_mismatch) WHERE location_mismatch = TRUE;
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_report_spam_flags_created_at;
+
+ALTER TABLE reports
+    DROP COLUMN IF EXISTS spam_decision,
+    DROP COLUMN IF EXISTS spam_reviewed_at;
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE reports
+    ADD COLUMN IF NOT EXISTS spam_reviewed_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS spam_decision VARCHAR(20)
+        CHECK (spam_decision IN ('restored', 'confirmed'));
+
+CREATE INDEX IF NOT EXISTS idx_report_spam_flags_created_at ON report_spam_flags(created_at);
\ No newline at end of file
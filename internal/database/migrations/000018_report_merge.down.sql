@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_reports_merged_into_id;
+
+ALTER TABLE reports
+    DROP COLUMN IF EXISTS merged_at,
+    DROP COLUMN IF EXISTS merged_into_id;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE reports
+    ADD COLUMN IF NOT EXISTS merged_into_id UUID REFERENCES reports(id),
+    ADD COLUMN IF NOT EXISTS merged_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_reports_merged_into_id ON reports(merged_into_id) WHERE merged_into_id IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_areas_parent_id;
+
+DROP TABLE IF EXISTS user_areas;
\ No newline at end of file
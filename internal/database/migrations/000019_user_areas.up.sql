@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS user_areas (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    area_id UUID NOT NULL REFERENCES areas(id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, area_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_areas_area_id ON user_areas(area_id);
+
+-- descendant lookups walk the hierarchy top down
+CREATE INDEX IF NOT EXISTS idx_areas_parent_id ON areas(parent_id);
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_reports_assigned_to;
+
+ALTER TABLE reports
+    DROP COLUMN IF EXISTS assigned_at,
+    DROP COLUMN IF EXISTS assigned_to;
\ No newline at end of file
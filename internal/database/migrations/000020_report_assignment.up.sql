@@ -0,0 +1,5 @@
+ALTER TABLE reports
+    ADD COLUMN IF NOT EXISTS assigned_to UUID REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_reports_assigned_to ON reports(assigned_to, status) WHERE assigned_to IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS report_escalations;
+
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS resolution_sla_minutes,
+    DROP COLUMN IF EXISTS response_sla_minutes;
\ No newline at end of file
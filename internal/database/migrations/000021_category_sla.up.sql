@@ -0,0 +1,19 @@
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS response_sla_minutes INTEGER CHECK (response_sla_minutes > 0),
+    ADD COLUMN IF NOT EXISTS resolution_sla_minutes INTEGER CHECK (resolution_sla_minutes > 0);
+
+-- one row per breached SLA of a report, so a breach is escalated only once
+CREATE TABLE IF NOT EXISTS report_escalations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    report_id UUID NOT NULL REFERENCES reports(id),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('response', 'resolution')),
+    -- area whose officials were notified, NULL escalates to admins
+    area_id UUID REFERENCES areas(id),
+    deadline TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE (report_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_escalations_area_id ON report_escalations(area_id);
+CREATE INDEX IF NOT EXISTS idx_report_escalations_created_at ON report_escalations(created_at);
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS calendar_holidays;
+
+DROP TABLE IF EXISTS calendar_working_hours;
+
+DROP TABLE IF EXISTS business_calendars;
\ No newline at end of file
@@ -0,0 +1,41 @@
+-- working calendar of an area and the areas below it, the row without an
+-- area is the default calendar and its holidays apply everywhere
+CREATE TABLE IF NOT EXISTS business_calendars (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    area_id UUID UNIQUE REFERENCES areas(id) ON DELETE CASCADE,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_business_calendars_default ON business_calendars((area_id IS NULL)) WHERE area_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS calendar_working_hours (
+    calendar_id UUID NOT NULL REFERENCES business_calendars(id) ON DELETE CASCADE,
+    -- 0 is Sunday
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+
+    PRIMARY KEY (calendar_id, weekday, start_time),
+    CHECK (end_time > start_time)
+);
+
+CREATE TABLE IF NOT EXISTS calendar_holidays (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    calendar_id UUID NOT NULL REFERENCES business_calendars(id) ON DELETE CASCADE,
+    holiday_date DATE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE (calendar_id, holiday_date)
+);
+
+-- default calendar, Monday to Friday 08:00-16:00
+INSERT INTO business_calendars (area_id, timezone) VALUES (NULL, 'Asia/Jakarta');
+
+INSERT INTO calendar_working_hours (calendar_id, weekday, start_time, end_time)
+SELECT c.id, d.weekday, '08:00', '16:00'
+FROM business_calendars c
+CROSS JOIN generate_series(1, 5) AS d(weekday)
+WHERE c.area_id IS NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS credibility_ledger;
\ No newline at end of file
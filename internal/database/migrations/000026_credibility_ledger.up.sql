@@ -0,0 +1,24 @@
+-- every change of a user's credibility score, so the score can be explained
+CREATE TABLE IF NOT EXISTS credibility_ledger (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event VARCHAR(30) NOT NULL CHECK (event IN ('report_resolved', 'upvote_received', 'spam_confirmed', 'report_hidden', 'manual_adjustment')),
+    delta SMALLINT NOT NULL,
+    score_before SMALLINT NOT NULL,
+    score_after SMALLINT NOT NULL,
+    status_before VARCHAR(20) NOT NULL,
+    status_after VARCHAR(20) NOT NULL,
+    report_id UUID REFERENCES reports(id),
+    -- user who caused the event (the voter of an upvote), NULL when automatic
+    actor_id UUID REFERENCES users(id),
+    reason TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_credibility_ledger_user_id ON credibility_ledger(user_id, created_at DESC);
+
+-- a report scores each event once, and each voter's upvote once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_credibility_ledger_report_event ON credibility_ledger(user_id, event, report_id)
+    WHERE event IN ('report_resolved', 'spam_confirmed', 'report_hidden');
+CREATE UNIQUE INDEX IF NOT EXISTS idx_credibility_ledger_upvote ON credibility_ledger(user_id, report_id, actor_id)
+    WHERE event = 'upvote_received';
\ No newline at end of file
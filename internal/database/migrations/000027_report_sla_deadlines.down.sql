@@ -0,0 +1 @@
+DROP TABLE IF EXISTS report_sla_deadlines;
\ No newline at end of file
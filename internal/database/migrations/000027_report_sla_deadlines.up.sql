@@ -0,0 +1,12 @@
+-- business time deadline of an SLA, computed once the wall-clock deadline has
+-- passed, so the report is not checked again before it; sla_minutes tells
+-- whether the category SLA changed since
+CREATE TABLE IF NOT EXISTS report_sla_deadlines (
+    report_id UUID NOT NULL REFERENCES reports(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('response', 'resolution')),
+    sla_minutes INTEGER NOT NULL,
+    due_at TIMESTAMPTZ NOT NULL,
+    computed_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (report_id, kind)
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS report_vote_changes;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- every change of the vote counters of a report, so the feed can rank
+-- reports by the votes they had when its first page was ranked
+CREATE TABLE IF NOT EXISTS report_vote_changes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    report_id UUID NOT NULL REFERENCES reports(id) ON DELETE CASCADE,
+    upvote_delta INTEGER NOT NULL,
+    downvote_delta INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_vote_changes_report_id ON report_vote_changes(report_id, created_at);
\ No newline at end of file
@@ -59,4 +59,27 @@ UPDATE
     areas
 SET
     is_active = NOT is_active
-WHERE id = @id RETURNING id, is_active;
\ No newline at end of file
+WHERE id = @id RETURNING id, is_active;
+
+-- name: FindAreaByPoint :one
+-- Returns the deepest active area (following parent_id) covering the point.
+WITH RECURSIVE matched AS (
+    SELECT id, parent_id
+    FROM areas
+    WHERE is_active = TRUE
+      AND deleted_at IS NULL
+      AND ST_Covers(boundary, ST_SetSRID(ST_MakePoint(@lng::float8, @lat::float8), 4326))
+), ancestry AS (
+    SELECT id AS area_id, parent_id, 0 AS depth
+    FROM matched
+    UNION ALL
+    SELECT an.area_id, p.parent_id, an.depth + 1
+    FROM ancestry an
+    JOIN areas p ON p.id = an.parent_id
+    WHERE an.depth < 10
+)
+SELECT area_id
+FROM ancestry
+GROUP BY area_id
+ORDER BY MAX(depth) DESC
+LIMIT 1;
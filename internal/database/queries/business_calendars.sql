@@ -0,0 +1,111 @@
+-- name: GetAreaCalendar :one
+-- The calendar of area_id or of its nearest ancestor that has one, falling
+-- back to the default calendar.
+WITH RECURSIVE ancestors AS (
+    SELECT a.id, a.parent_id, 0 AS depth
+    FROM areas a
+    WHERE a.id = sqlc.narg('area_id')::uuid
+    UNION
+    SELECT p.id, p.parent_id, an.depth + 1
+    FROM areas p
+    JOIN ancestors an ON p.id = an.parent_id
+)
+SELECT
+    c.id,
+    c.area_id,
+    c.timezone
+FROM business_calendars c
+LEFT JOIN ancestors an ON c.area_id = an.id
+WHERE c.area_id IS NULL OR an.id IS NOT NULL
+ORDER BY an.depth ASC NULLS LAST
+LIMIT 1;
+
+-- name: GetDefaultCalendar :one
+SELECT
+    id,
+    area_id,
+    timezone
+FROM business_calendars
+WHERE area_id IS NULL;
+
+-- name: GetCalendarByArea :one
+-- The calendar set on area_id itself, the default calendar when area_id is NULL.
+SELECT
+    id,
+    area_id,
+    timezone
+FROM business_calendars
+WHERE area_id IS NOT DISTINCT FROM sqlc.narg('area_id')::uuid;
+
+-- name: CreateBusinessCalendar :one
+INSERT INTO business_calendars (
+    area_id,
+    timezone
+) VALUES (
+    @area_id,
+    @timezone
+) RETURNING id;
+
+-- name: UpdateCalendarTimezone :exec
+UPDATE business_calendars
+SET timezone = @timezone, updated_at = NOW()
+WHERE id = @id;
+
+-- name: DeleteAreaCalendar :one
+DELETE FROM business_calendars
+WHERE area_id = @area_id::uuid
+RETURNING id;
+
+-- name: DeleteWorkingHours :exec
+DELETE FROM calendar_working_hours
+WHERE calendar_id = @calendar_id;
+
+-- name: CreateWorkingHours :exec
+INSERT INTO calendar_working_hours (
+    calendar_id,
+    weekday,
+    start_time,
+    end_time
+) VALUES (
+    @calendar_id,
+    @weekday,
+    @start_time,
+    @end_time
+);
+
+-- name: GetWorkingHours :many
+SELECT
+    weekday,
+    start_time,
+    end_time
+FROM calendar_working_hours
+WHERE calendar_id = @calendar_id
+ORDER BY weekday ASC, start_time ASC;
+
+-- name: UpsertHoliday :one
+INSERT INTO calendar_holidays (
+    calendar_id,
+    holiday_date,
+    name
+) VALUES (
+    @calendar_id,
+    @holiday_date,
+    @name
+)
+ON CONFLICT (calendar_id, holiday_date) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: DeleteHoliday :one
+DELETE FROM calendar_holidays
+WHERE id = @id
+RETURNING calendar_id;
+
+-- name: GetCalendarHolidays :many
+SELECT
+    id,
+    calendar_id,
+    holiday_date,
+    name
+FROM calendar_holidays
+WHERE calendar_id = ANY(@calendar_ids::uuid[])
+ORDER BY holiday_date ASC;
\ No newline at end of file
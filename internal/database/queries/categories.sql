@@ -31,6 +31,8 @@ SELECT
     color,
     is_active,
     sort_order,
+    response_sla_minutes,
+    resolution_sla_minutes,
     created_at,
     updated_at
 FROM categories
@@ -46,6 +48,8 @@ SELECT
     color,
     is_active,
     sort_order,
+    response_sla_minutes,
+    resolution_sla_minutes,
     created_at,
     updated_at
 FROM categories
@@ -129,6 +133,15 @@ SET
 WHERE id = @id AND deleted_at IS NULL 
 RETURNING id;
 
+-- name: UpdateCategorySLA :one
+-- NULL removes the deadline.
+UPDATE categories
+SET
+    response_sla_minutes = sqlc.narg('response_sla_minutes'),
+    resolution_sla_minutes = sqlc.narg('resolution_sla_minutes')
+WHERE id = @id AND deleted_at IS NULL
+RETURNING id, response_sla_minutes, resolution_sla_minutes;
+
 -- name: DeleteCategory :one
 UPDATE categories
 SET deleted_at = NOW()
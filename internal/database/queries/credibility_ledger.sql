@@ -0,0 +1,77 @@
+-- name: GetUserCredibilityForUpdate :one
+-- Locks the user row, so concurrent events are applied one after another.
+SELECT
+    u.credibility_score,
+    u.status,
+    r.name AS role_name
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id AND r.deleted_at IS NULL
+WHERE u.id = @id
+  AND u.deleted_at IS NULL
+FOR UPDATE OF u;
+
+-- name: CreateCredibilityEntry :one
+-- Returns no row when the event was already scored.
+INSERT INTO credibility_ledger (
+    user_id,
+    event,
+    delta,
+    score_before,
+    score_after,
+    status_before,
+    status_after,
+    report_id,
+    actor_id,
+    reason
+) VALUES (
+    @user_id,
+    @event,
+    @delta,
+    @score_before,
+    @score_after,
+    @status_before,
+    @status_after,
+    @report_id,
+    @actor_id,
+    @reason
+)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: GetReportPenalty :one
+-- Sums what the events already cost the user for the report.
+SELECT COALESCE(SUM(delta), 0)::integer AS taken
+FROM credibility_ledger
+WHERE user_id = @user_id
+  AND report_id = @report_id
+  AND event = ANY(@events::text[]);
+
+-- name: UpdateUserCredibility :exec
+UPDATE users
+SET
+    credibility_score = @credibility_score,
+    status = @status
+WHERE id = @id;
+
+-- name: GetCredibilityLedger :many
+-- Newest first.
+SELECT
+    l.id,
+    l.event,
+    l.delta,
+    l.score_before,
+    l.score_after,
+    l.status_before,
+    l.status_after,
+    l.report_id,
+    r.title AS report_title,
+    l.actor_id,
+    u.username AS actor_username,
+    l.reason,
+    l.created_at
+FROM credibility_ledger l
+LEFT JOIN reports r ON l.report_id = r.id
+LEFT JOIN users u ON l.actor_id = u.id
+WHERE l.user_id = @user_id
+ORDER BY l.created_at DESC, l.id DESC
+OFFSET @offset_count LIMIT @limit_count;
@@ -0,0 +1,33 @@
+-- name: UpsertDeviceToken :one
+-- Registering a token again moves it to the current user.
+INSERT INTO device_tokens (
+    user_id,
+    token,
+    platform,
+    locale
+) VALUES (
+    @user_id,
+    @token,
+    @platform,
+    @locale
+)
+ON CONFLICT (token) DO UPDATE
+SET user_id = EXCLUDED.user_id,
+    platform = EXCLUDED.platform,
+    locale = EXCLUDED.locale,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteDeviceToken :execrows
+DELETE FROM device_tokens
+WHERE token = @token AND user_id = @user_id;
+
+-- name: GetDeviceTokensByUsers :many
+SELECT id, user_id, token, platform, locale
+FROM device_tokens
+WHERE user_id = ANY(@user_ids::uuid[]);
+
+-- name: DeleteDeviceTokens :execrows
+-- Prunes tokens the push provider no longer accepts.
+DELETE FROM device_tokens
+WHERE token = ANY(@tokens::text[]);
@@ -0,0 +1,76 @@
+-- name: CreateOutboxEmail :one
+INSERT INTO email_outbox (
+    recipient_enc,
+    template,
+    locale,
+    subject,
+    text_body_enc,
+    html_body_enc
+) VALUES (
+    @recipient_enc,
+    @template,
+    @locale,
+    @subject,
+    @text_body_enc,
+    @html_body_enc
+)
+RETURNING id;
+
+-- name: ClaimDueEmails :many
+-- Leases due emails until lease_until, so concurrent workers do not send
+-- the same email twice. An email whose worker died is picked up again once
+-- the lease runs out.
+UPDATE email_outbox
+SET next_attempt_at = @lease_until::timestamptz
+WHERE id IN (
+    SELECT o.id
+    FROM email_outbox o
+    WHERE o.status = 'pending' AND o.next_attempt_at <= NOW()
+    ORDER BY o.next_attempt_at
+    LIMIT @limit_count
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkEmailSent :exec
+UPDATE email_outbox
+SET status = 'sent',
+    attempts = attempts + 1,
+    last_error = NULL,
+    sent_at = NOW()
+WHERE id = @id;
+
+-- name: MarkEmailFailed :exec
+-- Schedules another attempt, or gives up once max_attempts is reached.
+UPDATE email_outbox
+SET attempts = attempts + 1,
+    last_error = @last_error::text,
+    next_attempt_at = @next_attempt_at::timestamptz,
+    status = CASE WHEN attempts + 1 >= @max_attempts::int THEN 'failed' ELSE 'pending' END
+WHERE id = @id;
+
+-- name: GetOutboxEmails :many
+SELECT
+    id,
+    template,
+    locale,
+    subject,
+    status,
+    attempts,
+    last_error,
+    next_attempt_at,
+    sent_at,
+    created_at
+FROM email_outbox
+WHERE (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status')::text)
+ORDER BY created_at DESC, id DESC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: RetryOutboxEmail :one
+-- Only failed emails can be retried, with a fresh number of attempts.
+UPDATE email_outbox
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = NOW()
+WHERE id = @id AND status = 'failed'
+RETURNING id;
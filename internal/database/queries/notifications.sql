@@ -0,0 +1,58 @@
+-- name: CreateNotifications :many
+-- One notification per user in user_ids.
+INSERT INTO notifications (
+    user_id,
+    type,
+    report_id,
+    actor_id,
+    data
+)
+SELECT
+    u.user_id,
+    @type,
+    @report_id,
+    @actor_id,
+    @data
+FROM unnest(@user_ids::uuid[]) AS u(user_id)
+RETURNING *;
+
+-- name: GetNotifications :many
+-- Newest first, paginated by (created_at, id) cursor.
+SELECT
+    n.id,
+    n.type,
+    n.report_id,
+    r.title AS report_title,
+    n.actor_id,
+    u.username AS actor_username,
+    n.data,
+    n.read_at,
+    n.created_at
+FROM notifications n
+LEFT JOIN reports r ON n.report_id = r.id
+LEFT JOIN users u ON n.actor_id = u.id
+WHERE n.user_id = @user_id
+  AND (NOT @unread_only::boolean OR n.read_at IS NULL)
+  AND (
+      sqlc.narg('cursor_created_at')::timestamptz IS NULL
+      OR (n.created_at, n.id) < (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY n.created_at DESC, n.id DESC
+LIMIT @limit_count;
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*)
+FROM notifications
+WHERE user_id = @user_id AND read_at IS NULL;
+
+-- name: MarkNotificationRead :one
+-- No row is returned when the notification is not the user's.
+UPDATE notifications
+SET read_at = COALESCE(read_at, NOW())
+WHERE id = @id AND user_id = @user_id
+RETURNING id;
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_id = @user_id AND read_at IS NULL;
\ No newline at end of file
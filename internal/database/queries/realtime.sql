@@ -0,0 +1,3 @@
+-- name: NotifyRealtimeEvent :exec
+-- Relays an event to the other instances listening on channel.
+SELECT pg_notify(@channel::text, @payload::text);
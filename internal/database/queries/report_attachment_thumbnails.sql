@@ -0,0 +1,19 @@
+-- name: CreateReportAttachmentThumbnail :one
+INSERT INTO report_attachment_thumbnails (
+    attachment_id,
+    size,
+    width,
+    height,
+    file_url
+) VALUES (
+    @attachment_id,
+    @size,
+    @width,
+    @height,
+    @file_url
+) RETURNING *;
+
+-- name: GetReportAttachmentThumbnails :many
+SELECT * FROM report_attachment_thumbnails
+WHERE attachment_id = ANY(@attachment_ids::uuid[])
+ORDER BY width ASC;
@@ -0,0 +1,39 @@
+-- name: CreateReportAttachment :one
+INSERT INTO report_attachments (
+    report_id,
+    file_url,
+    file_type,
+    file_size
+) VALUES (
+    @report_id,
+    @file_url,
+    @file_type,
+    @file_size
+) RETURNING *;
+
+-- name: GetReportAttachments :many
+SELECT * FROM report_attachments
+WHERE report_id = @report_id
+ORDER BY created_at ASC;
+
+-- name: GetReportAttachmentByID :one
+SELECT * FROM report_attachments
+WHERE id = @id;
+
+-- name: CountReportAttachments :one
+SELECT COUNT(*) FROM report_attachments
+WHERE report_id = @report_id;
+
+-- name: DeleteReportAttachment :exec
+DELETE FROM report_attachments
+WHERE id = @id;
+
+-- name: UpdateReportAttachmentBlurhash :exec
+UPDATE report_attachments
+SET blurhash = @blurhash
+WHERE id = @id;
+
+-- name: MoveReportAttachments :exec
+UPDATE report_attachments
+SET report_id = @target_id
+WHERE report_id = ANY(@source_ids::uuid[]);
@@ -0,0 +1,112 @@
+-- name: CreateReportComment :one
+INSERT INTO report_comments (
+    report_id,
+    parent_id,
+    user_id,
+    content
+) VALUES (
+    @report_id,
+    @parent_id,
+    @user_id,
+    @content
+) RETURNING *;
+
+-- name: GetReportCommentByID :one
+SELECT * FROM report_comments
+WHERE id = @id;
+
+-- name: GetReportCommentDepth :one
+-- Depth of a comment in its thread, top-level comments have depth 1.
+WITH RECURSIVE ancestors AS (
+    SELECT id, parent_id, 1 AS depth
+    FROM report_comments
+    WHERE id = @id
+    UNION ALL
+    SELECT c.id, c.parent_id, a.depth + 1
+    FROM report_comments c
+    JOIN ancestors a ON c.id = a.parent_id
+    WHERE a.depth < 100
+)
+SELECT MAX(depth)::int AS depth FROM ancestors;
+
+-- name: UpdateReportComment :one
+UPDATE report_comments
+SET
+    content = @content,
+    updated_at = NOW()
+WHERE id = @id AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SoftDeleteReportComment :exec
+UPDATE report_comments
+SET deleted_at = NOW()
+WHERE id = @id AND deleted_at IS NULL;
+
+-- name: GetTopLevelReportComments :many
+-- Newest first, paginated by (created_at, id) cursor. Deleted comments are
+-- only kept when a reply below them is not deleted.
+SELECT
+    c.id,
+    c.report_id,
+    c.parent_id,
+    c.user_id,
+    u.username,
+    c.content,
+    c.created_at,
+    c.updated_at,
+    c.deleted_at
+FROM report_comments c
+LEFT JOIN users u ON c.user_id = u.id
+WHERE c.report_id = @report_id
+  AND c.parent_id IS NULL
+  AND (c.deleted_at IS NULL OR EXISTS (
+      WITH RECURSIVE descendants AS (
+          SELECT id, deleted_at
+          FROM report_comments
+          WHERE parent_id = c.id
+          UNION ALL
+          SELECT r.id, r.deleted_at
+          FROM report_comments r
+          JOIN descendants d ON r.parent_id = d.id
+      )
+      SELECT 1 FROM descendants WHERE deleted_at IS NULL
+  ))
+  AND (
+      sqlc.narg('cursor_created_at')::timestamptz IS NULL
+      OR (c.created_at, c.id) < (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY c.created_at DESC, c.id DESC
+LIMIT @limit_count;
+
+-- name: GetReportCommentReplies :many
+-- All replies below the given comments, down to max_depth levels.
+WITH RECURSIVE tree AS (
+    SELECT id, report_id, parent_id, user_id, content, created_at, updated_at, deleted_at, 1 AS depth
+    FROM report_comments
+    WHERE parent_id = ANY(@root_ids::uuid[])
+    UNION ALL
+    SELECT c.id, c.report_id, c.parent_id, c.user_id, c.content, c.created_at, c.updated_at, c.deleted_at, t.depth + 1
+    FROM report_comments c
+    JOIN tree t ON c.parent_id = t.id
+    WHERE t.depth < @max_depth::int
+)
+SELECT
+    t.id,
+    t.report_id,
+    t.parent_id,
+    t.user_id,
+    u.username,
+    t.content,
+    t.created_at,
+    t.updated_at,
+    t.deleted_at,
+    t.depth::int AS depth
+FROM tree t
+LEFT JOIN users u ON t.user_id = u.id
+ORDER BY t.created_at ASC, t.id ASC;
+
+-- name: MoveReportComments :exec
+-- Moves the comments of merged reports onto the target, threads stay intact.
+UPDATE report_comments
+SET report_id = @target_id
+WHERE report_id = ANY(@source_ids::uuid[]);
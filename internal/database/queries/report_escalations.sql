@@ -0,0 +1,185 @@
+-- name: GetSLACandidates :many
+-- Under review and open reports past the response or resolution SLA of their
+-- category in wall-clock time, which were not escalated for it yet. Business
+-- time never runs faster than wall-clock time, so every breach is among them.
+-- A report counts as responded to once someone changed its status.
+-- Reports whose business time deadline was computed earlier and is still
+-- ahead are left out until it passes.
+-- escalate_to is the parent of the report's area, NULL when there is none.
+-- Rows come in (deadline, report_id, kind) order after the given cursor.
+WITH deadlines AS (
+    SELECT
+        r.id AS report_id,
+        'response' AS kind,
+        r.created_at,
+        c.response_sla_minutes AS sla_minutes,
+        r.created_at + make_interval(mins => c.response_sla_minutes) AS deadline,
+        r.status,
+        r.area_id
+    FROM reports r
+    JOIN categories c ON r.category_id = c.id
+    WHERE c.response_sla_minutes IS NOT NULL
+      AND r.status IN ('under_review', 'open')
+      AND r.deleted_at IS NULL
+      AND NOT EXISTS (
+        SELECT 1
+        FROM report_status_history h
+        WHERE h.report_id = r.id
+          AND h.old_status <> h.new_status
+          AND h.changed_by IS NOT NULL
+      )
+    UNION ALL
+    SELECT
+        r.id AS report_id,
+        'resolution' AS kind,
+        r.created_at,
+        c.resolution_sla_minutes AS sla_minutes,
+        r.created_at + make_interval(mins => c.resolution_sla_minutes) AS deadline,
+        r.status,
+        r.area_id
+    FROM reports r
+    JOIN categories c ON r.category_id = c.id
+    WHERE c.resolution_sla_minutes IS NOT NULL
+      AND r.status IN ('under_review', 'open')
+      AND r.deleted_at IS NULL
+)
+SELECT
+    d.report_id,
+    d.kind::text AS kind,
+    d.created_at::timestamptz AS created_at,
+    d.sla_minutes::integer AS sla_minutes,
+    d.deadline::timestamptz AS deadline,
+    d.status,
+    d.area_id,
+    a.parent_id AS escalate_to,
+    p.name AS escalate_to_name
+FROM deadlines d
+LEFT JOIN areas a ON d.area_id = a.id
+LEFT JOIN areas p ON a.parent_id = p.id
+LEFT JOIN report_sla_deadlines sd
+    ON sd.report_id = d.report_id
+   AND sd.kind = d.kind
+   AND sd.sla_minutes = d.sla_minutes
+WHERE d.deadline < NOW()
+  AND (sd.due_at IS NULL OR sd.due_at < NOW())
+  AND (d.deadline, d.report_id, d.kind) > (@after_deadline::timestamptz, @after_report_id::uuid, @after_kind::text)
+  AND NOT EXISTS (
+    SELECT 1
+    FROM report_escalations e
+    WHERE e.report_id = d.report_id AND e.kind = d.kind
+  )
+ORDER BY d.deadline ASC, d.report_id ASC, d.kind ASC
+LIMIT @limit_count;
+
+-- name: SetSLADeadline :exec
+INSERT INTO report_sla_deadlines (
+    report_id,
+    kind,
+    sla_minutes,
+    due_at
+) VALUES (
+    @report_id,
+    @kind,
+    @sla_minutes,
+    @due_at
+)
+ON CONFLICT (report_id, kind) DO UPDATE
+SET
+    sla_minutes = EXCLUDED.sla_minutes,
+    due_at = EXCLUDED.due_at,
+    computed_at = NOW();
+
+-- name: ClearSLADeadlines :exec
+-- Forgets every computed deadline, for when business calendars change.
+DELETE FROM report_sla_deadlines;
+
+-- name: CreateReportEscalation :one
+-- Returns no rows when the report was already escalated for this kind.
+INSERT INTO report_escalations (
+    report_id,
+    kind,
+    area_id,
+    deadline
+) VALUES (
+    @report_id,
+    @kind,
+    @area_id,
+    @deadline
+)
+ON CONFLICT (report_id, kind) DO NOTHING
+RETURNING id;
+
+-- name: GetEscalations :many
+SELECT
+    e.id,
+    e.report_id,
+    r.title,
+    r.status,
+    e.kind,
+    e.area_id,
+    a.name AS area_name,
+    e.deadline,
+    e.created_at
+FROM report_escalations e
+JOIN reports r ON e.report_id = r.id
+LEFT JOIN areas a ON e.area_id = a.id
+WHERE (sqlc.narg('area_ids')::uuid[] IS NULL OR e.area_id = ANY(sqlc.narg('area_ids')::uuid[]))
+  AND (sqlc.narg('kind')::text IS NULL OR e.kind = sqlc.narg('kind')::text)
+  AND (NOT @open_only::boolean OR r.status IN ('under_review', 'open'))
+ORDER BY e.created_at DESC, e.id DESC
+OFFSET @offset_count LIMIT @limit_count;
+
+-- name: GetReportSLA :one
+-- What the SLA of a report is measured against: when it was first responded
+-- to (its status changed by someone) and when it was first resolved or hidden.
+SELECT
+    r.id,
+    r.created_at::timestamptz AS created_at,
+    r.status,
+    r.area_id,
+    c.response_sla_minutes,
+    c.resolution_sla_minutes,
+    (
+        SELECT MIN(h.created_at)
+        FROM report_status_history h
+        WHERE h.report_id = r.id
+          AND h.old_status <> h.new_status
+          AND h.changed_by IS NOT NULL
+    ) AS responded_at,
+    (
+        SELECT MIN(h.created_at)
+        FROM report_status_history h
+        WHERE h.report_id = r.id
+          AND h.old_status <> h.new_status
+          AND h.new_status IN ('resolved', 'hidden')
+    ) AS resolved_at
+FROM reports r
+JOIN categories c ON r.category_id = c.id
+WHERE r.id = @id AND r.deleted_at IS NULL;
+
+-- name: GetEscalationRecipients :many
+-- Officials whose jurisdiction covers area_id, the admins when area_id is NULL.
+WITH RECURSIVE ancestors AS (
+    SELECT a.id, a.parent_id
+    FROM areas a
+    WHERE a.id = sqlc.narg('area_id')::uuid
+    UNION
+    SELECT p.id, p.parent_id
+    FROM areas p
+    JOIN ancestors an ON p.id = an.parent_id
+)
+SELECT u.id
+FROM users u
+JOIN roles ro ON u.role_id = ro.id
+WHERE u.deleted_at IS NULL
+  AND ro.deleted_at IS NULL
+  AND u.status <> 'suspended'
+  AND (
+    (sqlc.narg('area_id')::uuid IS NULL AND ro.name = 'admin')
+    OR (ro.name = 'official' AND EXISTS (
+        SELECT 1
+        FROM user_areas ua
+        JOIN ancestors an ON ua.area_id = an.id
+        WHERE ua.user_id = u.id
+    ))
+  );
\ No newline at end of file
@@ -0,0 +1,53 @@
+-- name: CreateReportSpamFlag :one
+-- Returns no row when the user already flagged the report.
+INSERT INTO report_spam_flags (
+    report_id,
+    user_id,
+    reason
+) VALUES (
+    @report_id,
+    @user_id,
+    @reason
+)
+ON CONFLICT (report_id, user_id) DO NOTHING
+RETURNING *;
+
+-- name: GetReportSpamWeight :one
+-- Sum of the flaggers' credibility scores over the flags not reviewed by a moderator yet.
+SELECT COALESCE(SUM(GREATEST(COALESCE(u.credibility_score, 0), 0)), 0)::float8 AS weight
+FROM report_spam_flags f
+JOIN reports r ON f.report_id = r.id
+JOIN users u ON f.user_id = u.id
+WHERE f.report_id = @report_id
+  AND (r.spam_reviewed_at IS NULL OR f.created_at > r.spam_reviewed_at);
+
+-- name: GetSpamQueue :many
+-- Reports with flags waiting for a moderator, heaviest first.
+SELECT
+    r.id,
+    r.title,
+    r.status,
+    r.user_id,
+    u.username,
+    r.created_at,
+    COUNT(f.id) AS flag_count,
+    COALESCE(SUM(GREATEST(COALESCE(fu.credibility_score, 0), 0)), 0)::float8 AS flag_weight,
+    array_agg(DISTINCT f.reason)::text[] AS reasons,
+    MAX(f.created_at)::timestamptz AS last_flagged_at
+FROM reports r
+JOIN users u ON r.user_id = u.id
+JOIN report_spam_flags f ON f.report_id = r.id
+    AND (r.spam_reviewed_at IS NULL OR f.created_at > r.spam_reviewed_at)
+JOIN users fu ON f.user_id = fu.id
+WHERE r.deleted_at IS NULL
+GROUP BY r.id, u.username
+ORDER BY flag_weight DESC, last_flagged_at DESC
+OFFSET @offset_count LIMIT @limit_count;
+
+-- name: MarkReportSpamReviewed :exec
+-- Closes the pending flags of a report, later flags start a new review.
+UPDATE reports
+SET
+    spam_reviewed_at = NOW(),
+    spam_decision = @decision::text
+WHERE id = @id;
\ No newline at end of file
@@ -0,0 +1,36 @@
+-- name: CreateReportStatusHistory :one
+INSERT INTO report_status_history (
+    report_id,
+    old_status,
+    new_status,
+    remark,
+    changed_by
+) VALUES (
+    @report_id,
+    @old_status,
+    @new_status,
+    @remark,
+    @changed_by
+) RETURNING *;
+
+-- name: GetReportStatusHistory :many
+SELECT
+    h.id,
+    h.old_status,
+    h.new_status,
+    h.remark,
+    h.changed_by,
+    u.username AS changed_by_username,
+    h.created_at
+FROM report_status_history h
+LEFT JOIN users u ON h.changed_by = u.id
+WHERE h.report_id = @report_id
+ORDER BY h.created_at ASC;
+
+-- name: GetStatusBeforeHidden :one
+-- The status a report had before it was last hidden.
+SELECT old_status
+FROM report_status_history
+WHERE report_id = @report_id AND new_status = 'hidden'
+ORDER BY created_at DESC
+LIMIT 1;
\ No newline at end of file
@@ -0,0 +1,41 @@
+-- name: FlushReportViews :exec
+-- Stores buffered views, sessions already seen are ignored, and adds the newly stored views to the report counters.
+WITH inserted AS (
+    INSERT INTO report_views (report_id, session_id, user_id, viewed_at)
+    SELECT report_id, session_id, user_id, viewed_at FROM unnest(
+        @report_ids::uuid[],
+        @session_ids::uuid[],
+        @user_ids::uuid[],
+        @viewed_ats::timestamptz[]
+    ) AS v(report_id, session_id, user_id, viewed_at)
+    ON CONFLICT (report_id, session_id) DO NOTHING
+    RETURNING report_id
+), counts AS (
+    SELECT report_id, COUNT(*) AS views
+    FROM inserted
+    GROUP BY report_id
+)
+UPDATE reports r
+SET view_count = COALESCE(r.view_count, 0) + counts.views
+FROM counts
+WHERE r.id = counts.report_id;
+-- name: MoveReportViews :exec
+-- Moves the views of merged reports onto the target, a session that saw several of them counts once.
+WITH moved AS (
+    DELETE FROM report_views
+    WHERE report_id = ANY(@source_ids::uuid[])
+    RETURNING session_id, user_id, viewed_at
+)
+INSERT INTO report_views (
+    report_id,
+    session_id,
+    user_id,
+    viewed_at
+)
+SELECT
+    @target_id,
+    m.session_id,
+    m.user_id,
+    m.viewed_at
+FROM moved m
+ON CONFLICT (report_id, session_id) DO NOTHING;
@@ -0,0 +1,67 @@
+-- name: GetReportVote :one
+SELECT * FROM report_votes
+WHERE report_id = @report_id AND user_id = @user_id;
+
+-- name: CreateReportVote :one
+INSERT INTO report_votes (
+    report_id,
+    user_id,
+    vote_type
+) VALUES (
+    @report_id,
+    @user_id,
+    @vote_type
+) RETURNING *;
+
+-- name: UpdateReportVoteType :exec
+UPDATE report_votes
+SET vote_type = @vote_type
+WHERE report_id = @report_id AND user_id = @user_id;
+
+-- name: DeleteReportVote :exec
+DELETE FROM report_votes
+WHERE report_id = @report_id AND user_id = @user_id;
+
+-- name: UpdateReportVoteCounts :one
+-- Applies vote deltas to the denormalized counters and logs them to report_vote_changes, must run in
+-- the same transaction as the vote change.
+WITH logged AS (
+    INSERT INTO report_vote_changes (
+        upvote_delta,
+        downvote_delta,
+        report_id
+    )
+    SELECT @upvote_delta::int, @downvote_delta::int, @id::uuid
+    WHERE @upvote_delta::int <> 0 OR @downvote_delta::int <> 0
+)
+UPDATE reports
+SET
+    upvote_count = COALESCE(upvote_count, 0) + @upvote_delta::int,
+    downvote_count = COALESCE(downvote_count, 0) + @downvote_delta::int
+WHERE id = @id
+RETURNING upvote_count, downvote_count;
+
+-- name: MoveReportVotes :exec
+-- Moves the votes of merged reports onto the target. A user keeps at most one vote on the target:
+-- an existing vote there wins, otherwise their latest vote on a source. Votes by the target's own
+-- reporter are dropped.
+WITH moved AS (
+    DELETE FROM report_votes
+    WHERE report_id = ANY(@source_ids::uuid[])
+    RETURNING user_id, vote_type, created_at
+)
+INSERT INTO report_votes (
+    report_id,
+    user_id,
+    vote_type,
+    created_at
+)
+SELECT DISTINCT ON (m.user_id)
+    @target_id,
+    m.user_id,
+    m.vote_type,
+    m.created_at
+FROM moved m
+WHERE m.user_id <> (SELECT user_id FROM reports WHERE id = @target_id)
+ORDER BY m.user_id, m.created_at DESC
+ON CONFLICT (report_id, user_id) DO NOTHING;
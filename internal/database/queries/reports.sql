@@ -0,0 +1,443 @@
+-- name: CreateReport :one
+INSERT INTO reports (
+    title,
+    description,
+    address,
+    location,
+    area_id,
+    category_id,
+    user_id
+) VALUES (
+    @title,
+    @description,
+    @address,
+    ST_SetSRID(ST_MakePoint(@lng::float8, @lat::float8), 4326),
+    @area_id,
+    @category_id,
+    @user_id
+) RETURNING id;
+
+-- name: GetReportByID :one
+SELECT
+    r.id,
+    r.title,
+    r.description,
+    r.address,
+    ST_Y(r.location)::float8 AS lat,
+    ST_X(r.location)::float8 AS lng,
+    r.area_id,
+    a.name AS area_name,
+    r.category_id,
+    c.name AS category_name,
+    r.user_id,
+    u.username,
+    r.status,
+    r.view_count,
+    r.upvote_count,
+    r.downvote_count,
+    r.location_mismatch,
+    r.location_mismatch_reason,
+    r.merged_into_id,
+    r.assigned_to,
+    r.assigned_at,
+    v.vote_type AS my_vote,
+    r.resolved_at,
+    r.created_at,
+    r.updated_at
+FROM reports r
+JOIN categories c ON r.category_id = c.id
+JOIN users u ON r.user_id = u.id
+LEFT JOIN areas a ON r.area_id = a.id
+LEFT JOIN report_votes v ON v.report_id = r.id AND v.user_id = @user_id
+WHERE r.id = @id AND r.deleted_at IS NULL;
+
+-- name: GetReportsByUser :many
+SELECT
+    r.id,
+    r.title,
+    r.address,
+    ST_Y(r.location)::float8 AS lat,
+    ST_X(r.location)::float8 AS lng,
+    r.area_id,
+    a.name AS area_name,
+    r.category_id,
+    c.name AS category_name,
+    r.status,
+    r.view_count,
+    r.upvote_count,
+    r.downvote_count,
+    r.resolved_at,
+    r.created_at
+FROM reports r
+JOIN categories c ON r.category_id = c.id
+LEFT JOIN areas a ON r.area_id = a.id
+WHERE r.user_id = @user_id AND r.deleted_at IS NULL
+ORDER BY r.created_at DESC
+OFFSET @offset_count LIMIT @limit_count;
+
+-- name: GetReports :many
+SELECT
+    r.id,
+    r.title,
+    r.address,
+    ST_Y(r.location)::float8 AS lat,
+    ST_X(r.location)::float8 AS lng,
+    r.area_id,
+    a.name AS area_name,
+    r.category_id,
+    c.name AS category_name,
+    r.user_id,
+    u.username,
+    r.status,
+    r.view_count,
+    r.upvote_count,
+    r.downvote_count,
+    r.location_mismatch,
+    r.resolved_at,
+    r.created_at
+FROM reports r
+JOIN categories c ON r.category_id = c.id
+JOIN users u ON r.user_id = u.id
+LEFT JOIN areas a ON r.area_id = a.id
+WHERE r.deleted_at IS NULL
+  AND (@public_only::boolean = FALSE OR r.status IN ('open', 'resolved'))
+  AND (sqlc.narg('area_ids')::uuid[] IS NULL OR r.area_id = ANY(sqlc.narg('area_ids')::uuid[]))
+  AND (sqlc.narg('status')::text IS NULL OR r.status = sqlc.narg('status')::text)
+  AND (sqlc.narg('category_id')::uuid IS NULL OR r.category_id = sqlc.narg('category_id')::uuid)
+  AND (sqlc.narg('area_id')::uuid IS NULL OR r.area_id = sqlc.narg('area_id')::uuid)
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR r.created_at >= sqlc.narg('created_from')::timestamptz)
+  AND (sqlc.narg('created_to')::timestamptz IS NULL OR r.created_at < sqlc.narg('created_to')::timestamptz)
+  AND (sqlc.narg('location_mismatch')::boolean IS NULL OR r.location_mismatch = sqlc.narg('location_mismatch')::boolean)
+  AND r.title ILIKE '%' || @search_term::text || '%'
+ORDER BY r.created_at DESC
+OFFSET @offset_count LIMIT @limit_count;
+
+-- name: GetReportForUpdate :one
+SELECT
+    id,
+    user_id,
+    area_id,
+    status,
+    merged_into_id,
+    assigned_to
+FROM reports
+WHERE id = @id AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: UpdateReportStatus :exec
+UPDATE reports
+SET
+    status = @status::text,
+    resolved_at = CASE WHEN @status::text = 'resolved' THEN NOW() ELSE NULL END,
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: FlagReportLocationMismatch :exec
+UPDATE reports
+SET
+    location_mismatch = TRUE,
+    location_mismatch_reason = @reason::text
+WHERE id = @id;
+
+
+-- name: GetNearbyReports :many
+-- Reports inside a bounding box, optionally also within radius_meters of the caller, closest first.
+-- The && check on the envelope is served by the GIST index on location, the exact
+-- geography distance is only computed for the rows it lets through.
+SELECT
+    r.id,
+    r.title,
+    r.address,
+    ST_Y(r.location)::float8 AS lat,
+    ST_X(r.location)::float8 AS lng,
+    r.area_id,
+    a.name AS area_name,
+    r.category_id,
+    c.name AS category_name,
+    r.user_id,
+    u.username,
+    r.status,
+    r.view_count,
+    r.upvote_count,
+    r.downvote_count,
+    r.resolved_at,
+    r.created_at,
+    ST_Distance(r.location::geography, ST_SetSRID(ST_MakePoint(@lng::float8, @lat::float8), 4326)::geography)::float8 AS distance_meters
+FROM reports r
+JOIN categories c ON r.category_id = c.id
+JOIN users u ON r.user_id = u.id
+LEFT JOIN areas a ON r.area_id = a.id
+WHERE r.deleted_at IS NULL
+  AND r.location && ST_MakeEnvelope(@min_lng::float8, @min_lat::float8, @max_lng::float8, @max_lat::float8, 4326)
+  AND (sqlc.narg('radius_meters')::float8 IS NULL OR ST_DWithin(
+        r.location::geography,
+        ST_SetSRID(ST_MakePoint(@lng::float8, @lat::float8), 4326)::geography,
+        sqlc.narg('radius_meters')::float8
+      ))
+  AND (@public_only::boolean = FALSE OR r.status IN ('open', 'resolved'))
+  AND (sqlc.narg('area_ids')::uuid[] IS NULL OR r.area_id = ANY(sqlc.narg('area_ids')::uuid[]))
+  AND (sqlc.narg('status')::text IS NULL OR r.status = sqlc.narg('status')::text)
+  AND (sqlc.narg('category_id')::uuid IS NULL OR r.category_id = sqlc.narg('category_id')::uuid)
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR r.created_at >= sqlc.narg('created_from')::timestamptz)
+  AND (sqlc.narg('created_to')::timestamptz IS NULL OR r.created_at < sqlc.narg('created_to')::timestamptz)
+ORDER BY distance_meters ASC, r.id ASC
+OFFSET @offset_count LIMIT @limit_count;
+
+-- name: GetReportClusters :many
+-- Groups the reports inside a bounding box into square web mercator cells of cell_size meters,
+-- one row per non-empty cell with its count, centroid, extent and per-category counts.
+WITH points AS (
+    SELECT
+        r.id,
+        r.category_id,
+        r.location,
+        floor(ST_X(ST_Transform(r.location, 3857)) / @cell_size::float8)::bigint AS cell_x,
+        floor(ST_Y(ST_Transform(r.location, 3857)) / @cell_size::float8)::bigint AS cell_y
+    FROM reports r
+    WHERE r.deleted_at IS NULL
+      AND r.location && ST_MakeEnvelope(@min_lng::float8, @min_lat::float8, @max_lng::float8, @max_lat::float8, 4326)
+      AND (@public_only::boolean = FALSE OR r.status IN ('open', 'resolved'))
+      AND (sqlc.narg('area_ids')::uuid[] IS NULL OR r.area_id = ANY(sqlc.narg('area_ids')::uuid[]))
+      AND (sqlc.narg('status')::text IS NULL OR r.status = sqlc.narg('status')::text)
+      AND (sqlc.narg('category_id')::uuid IS NULL OR r.category_id = sqlc.narg('category_id')::uuid)
+      AND (sqlc.narg('created_from')::timestamptz IS NULL OR r.created_at >= sqlc.narg('created_from')::timestamptz)
+      AND (sqlc.narg('created_to')::timestamptz IS NULL OR r.created_at < sqlc.narg('created_to')::timestamptz)
+), cell_categories AS (
+    SELECT
+        pc.cell_x,
+        pc.cell_y,
+        jsonb_agg(jsonb_build_object(
+            'category_id', c.id,
+            'category_name', c.name,
+            'count', pc.point_count
+        ) ORDER BY pc.point_count DESC, c.name) AS categories
+    FROM (
+        SELECT cell_x, cell_y, category_id, COUNT(*) AS point_count
+        FROM points
+        GROUP BY cell_x, cell_y, category_id
+    ) pc
+    JOIN categories c ON pc.category_id = c.id
+    GROUP BY pc.cell_x, pc.cell_y
+)
+SELECT
+    COUNT(*) AS point_count,
+    ST_Y(ST_Centroid(ST_Collect(p.location)))::float8 AS lat,
+    ST_X(ST_Centroid(ST_Collect(p.location)))::float8 AS lng,
+    ST_XMin(ST_Extent(p.location))::float8 AS min_lng,
+    ST_YMin(ST_Extent(p.location))::float8 AS min_lat,
+    ST_XMax(ST_Extent(p.location))::float8 AS max_lng,
+    ST_YMax(ST_Extent(p.location))::float8 AS max_lat,
+    (array_agg(p.id))[1]::uuid AS report_id,
+    cc.categories::jsonb AS categories
+FROM points p
+JOIN cell_categories cc ON cc.cell_x = p.cell_x AND cc.cell_y = p.cell_y
+GROUP BY p.cell_x, p.cell_y, cc.categories
+ORDER BY point_count DESC;
+
+-- name: GetReportFeed :many
+-- One page of the report feed. Every sort mode is reduced to a single rank_score, highest first:
+-- hot decays (net votes + 1) weighted by the reporter's credibility with the report's age at as_of,
+-- top is the net votes, new the creation time and nearby the negated distance to the caller.
+-- Votes and credibility are taken as they were at as_of: vote counter changes logged after it are
+-- taken back, and the credibility is the score before the reporter's first ledger entry after it.
+-- Reports created after as_of are left out, so every page ranks the same reports the same way.
+WITH ranked AS (
+    SELECT
+        r.id,
+        r.title,
+        r.address,
+        ST_Y(r.location)::float8 AS lat,
+        ST_X(r.location)::float8 AS lng,
+        r.area_id,
+        a.name AS area_name,
+        r.category_id,
+        c.name AS category_name,
+        r.user_id,
+        u.username,
+        r.status,
+        r.view_count,
+        r.upvote_count,
+        r.downvote_count,
+        r.resolved_at,
+        r.created_at,
+        (CASE @sort_mode::text
+            WHEN 'hot' THEN
+                (COALESCE(r.upvote_count, 0) - COALESCE(r.downvote_count, 0) - later.net_votes + 1)
+                * (0.5 + GREATEST(COALESCE(
+                    (
+                        SELECT l.score_before
+                        FROM credibility_ledger l
+                        WHERE l.user_id = r.user_id
+                          AND l.created_at > @as_of::timestamptz
+                        ORDER BY l.created_at ASC, l.id ASC
+                        LIMIT 1
+                    ),
+                    u.credibility_score,
+                    0
+                ), 0) / 100.0)
+                / power(GREATEST(EXTRACT(EPOCH FROM (@as_of::timestamptz - r.created_at)) / 3600, 0) + 2, @gravity::float8)
+            WHEN 'top' THEN
+                COALESCE(r.upvote_count, 0) - COALESCE(r.downvote_count, 0) - later.net_votes
+            WHEN 'nearby' THEN
+                -ST_Distance(
+                    r.location::geography,
+                    ST_SetSRID(ST_MakePoint(sqlc.narg('lng')::float8, sqlc.narg('lat')::float8), 4326)::geography
+                )
+            ELSE
+                EXTRACT(EPOCH FROM r.created_at)
+        END)::float8 AS rank_score
+    FROM reports r
+    JOIN categories c ON r.category_id = c.id
+    JOIN users u ON r.user_id = u.id
+    LEFT JOIN areas a ON r.area_id = a.id
+    CROSS JOIN LATERAL (
+        SELECT COALESCE(SUM(vc.upvote_delta - vc.downvote_delta), 0) AS net_votes
+        FROM report_vote_changes vc
+        WHERE vc.report_id = r.id
+          AND vc.created_at > @as_of::timestamptz
+    ) later
+    WHERE r.deleted_at IS NULL
+      AND r.created_at <= @as_of::timestamptz
+      AND r.merged_into_id IS NULL
+      AND (@public_only::boolean = FALSE OR r.status IN ('open', 'resolved'))
+      AND (sqlc.narg('area_ids')::uuid[] IS NULL OR r.area_id = ANY(sqlc.narg('area_ids')::uuid[]))
+      AND (sqlc.narg('created_from')::timestamptz IS NULL OR r.created_at >= sqlc.narg('created_from')::timestamptz)
+      AND (sqlc.narg('category_id')::uuid IS NULL OR r.category_id = sqlc.narg('category_id')::uuid)
+      AND (sqlc.narg('min_lng')::float8 IS NULL OR (
+            r.location && ST_MakeEnvelope(sqlc.narg('min_lng')::float8, sqlc.narg('min_lat')::float8, sqlc.narg('max_lng')::float8, sqlc.narg('max_lat')::float8, 4326)
+            AND ST_DWithin(
+                r.location::geography,
+                ST_SetSRID(ST_MakePoint(sqlc.narg('lng')::float8, sqlc.narg('lat')::float8), 4326)::geography,
+                sqlc.narg('radius_meters')::float8
+            )
+          ))
+)
+SELECT *
+FROM ranked
+WHERE sqlc.narg('cursor_score')::float8 IS NULL
+   OR (rank_score, id) < (sqlc.narg('cursor_score')::float8, sqlc.narg('cursor_id')::uuid)
+ORDER BY rank_score DESC, id DESC
+LIMIT @limit_count;
+-- name: FindDuplicateReports :many
+-- Open or under-review reports of the same category near a point, created since created_from,
+-- whose title or description is at least min_similarity alike (pg_trgm), most similar first.
+SELECT
+    r.id,
+    r.title,
+    r.address,
+    ST_Y(r.location)::float8 AS lat,
+    ST_X(r.location)::float8 AS lng,
+    r.category_id,
+    c.name AS category_name,
+    r.status,
+    r.upvote_count,
+    r.downvote_count,
+    r.created_at,
+    ST_Distance(r.location::geography, ST_SetSRID(ST_MakePoint(@lng::float8, @lat::float8), 4326)::geography)::float8 AS distance_meters,
+    GREATEST(similarity(r.title, @title::text), similarity(r.description, @description::text))::float8 AS similarity
+FROM reports r
+JOIN categories c ON r.category_id = c.id
+WHERE r.deleted_at IS NULL
+  AND r.status IN ('open', 'under_review')
+  AND r.category_id = @category_id
+  AND r.created_at >= @created_from::timestamptz
+  AND r.location && ST_MakeEnvelope(@min_lng::float8, @min_lat::float8, @max_lng::float8, @max_lat::float8, 4326)
+  AND ST_DWithin(
+        r.location::geography,
+        ST_SetSRID(ST_MakePoint(@lng::float8, @lat::float8), 4326)::geography,
+        @radius_meters::float8
+      )
+  AND GREATEST(similarity(r.title, @title::text), similarity(r.description, @description::text)) >= @min_similarity::float8
+ORDER BY similarity DESC, distance_meters ASC
+LIMIT @limit_count;
+
+-- name: MarkReportMerged :exec
+UPDATE reports
+SET
+    status = 'hidden',
+    merged_into_id = @merged_into_id,
+    merged_at = NOW(),
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: RepointMergedReports :exec
+-- Reports merged earlier into one of the sources now point at the new target.
+UPDATE reports
+SET merged_into_id = @target_id
+WHERE merged_into_id = ANY(@source_ids::uuid[]);
+
+-- name: RecountReportCounters :one
+-- Recomputes the denormalized view and vote counters from report_views and report_votes, and logs
+-- how the vote counters moved to report_vote_changes.
+WITH counts AS (
+    SELECT
+        (SELECT COUNT(*) FROM report_views WHERE report_id = @id) AS views,
+        (SELECT COUNT(*) FROM report_votes WHERE report_id = @id AND vote_type = 'upvote') AS upvotes,
+        (SELECT COUNT(*) FROM report_votes WHERE report_id = @id AND vote_type = 'downvote') AS downvotes
+), logged AS (
+    INSERT INTO report_vote_changes (
+        report_id,
+        upvote_delta,
+        downvote_delta
+    )
+    SELECT
+        r.id,
+        c.upvotes - COALESCE(r.upvote_count, 0),
+        c.downvotes - COALESCE(r.downvote_count, 0)
+    FROM reports r, counts c
+    WHERE r.id = @id
+      AND (c.upvotes <> COALESCE(r.upvote_count, 0) OR c.downvotes <> COALESCE(r.downvote_count, 0))
+)
+UPDATE reports r
+SET
+    view_count = c.views,
+    upvote_count = c.upvotes,
+    downvote_count = c.downvotes,
+    updated_at = NOW()
+FROM counts c
+WHERE r.id = @id
+RETURNING r.view_count, r.upvote_count, r.downvote_count;
+
+-- name: UpdateReportAssignee :exec
+UPDATE reports
+SET
+    assigned_to = @assigned_to,
+    assigned_at = NOW(),
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: GetAssignedReports :many
+SELECT
+    r.id,
+    r.title,
+    r.address,
+    ST_Y(r.location)::float8 AS lat,
+    ST_X(r.location)::float8 AS lng,
+    r.area_id,
+    a.name AS area_name,
+    r.category_id,
+    c.name AS category_name,
+    r.status,
+    r.upvote_count,
+    r.downvote_count,
+    r.assigned_at,
+    r.created_at
+FROM reports r
+JOIN categories c ON r.category_id = c.id
+LEFT JOIN areas a ON r.area_id = a.id
+WHERE r.deleted_at IS NULL
+  AND r.assigned_to = @assigned_to
+  AND (sqlc.narg('status')::text IS NULL OR r.status = sqlc.narg('status')::text)
+  AND (sqlc.narg('category_id')::uuid IS NULL OR r.category_id = sqlc.narg('category_id')::uuid)
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR r.created_at >= sqlc.narg('created_from')::timestamptz)
+  AND (sqlc.narg('created_to')::timestamptz IS NULL OR r.created_at < sqlc.narg('created_to')::timestamptz)
+ORDER BY r.assigned_at DESC, r.id DESC
+OFFSET @offset_count LIMIT @limit_count;
+
+-- name: CountAssignedReportsByStatus :many
+SELECT
+    r.status,
+    COUNT(*) AS count
+FROM reports r
+WHERE r.deleted_at IS NULL AND r.assigned_to = @assigned_to
+GROUP BY r.status;
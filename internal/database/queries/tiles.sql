@@ -0,0 +1,45 @@
+-- name: GetAreaTile :one
+-- Areas intersecting tile z/x/y, encoded as the "areas" layer of a Mapbox Vector Tile.
+WITH bounds AS (
+    SELECT ST_TileEnvelope(@z::int, @x::int, @y::int) AS geom
+), mvt AS (
+    SELECT
+        a.id::text AS id,
+        a.name,
+        a.area_code,
+        a.area_type,
+        a.parent_id::text AS parent_id,
+        COALESCE(a.is_active, FALSE) AS is_active,
+        ST_AsMVTGeom(ST_Transform(a.boundary, 3857), b.geom) AS geom
+    FROM areas a
+    CROSS JOIN bounds b
+    WHERE a.deleted_at IS NULL
+      AND a.boundary && ST_Transform(b.geom, 4326)
+)
+SELECT COALESCE(ST_AsMVT(mvt, 'areas', 4096, 'geom'), ''::bytea)::bytea AS tile
+FROM mvt;
+
+-- name: GetReportTile :one
+-- Reports inside tile z/x/y, encoded as the "reports" layer of a Mapbox Vector Tile.
+WITH bounds AS (
+    SELECT ST_TileEnvelope(@z::int, @x::int, @y::int) AS geom
+), mvt AS (
+    SELECT
+        r.id::text AS id,
+        r.title,
+        r.status,
+        r.category_id::text AS category_id,
+        c.name AS category_name,
+        COALESCE(r.upvote_count, 0) AS upvote_count,
+        EXTRACT(EPOCH FROM r.created_at)::bigint AS created_at,
+        ST_AsMVTGeom(ST_Transform(r.location, 3857), b.geom) AS geom
+    FROM reports r
+    JOIN categories c ON r.category_id = c.id
+    CROSS JOIN bounds b
+    WHERE r.deleted_at IS NULL
+      AND r.location && ST_Transform(b.geom, 4326)
+      AND (@public_only::boolean = FALSE OR r.status IN ('open', 'resolved'))
+      AND (sqlc.narg('area_ids')::uuid[] IS NULL OR r.area_id = ANY(sqlc.narg('area_ids')::uuid[]))
+)
+SELECT COALESCE(ST_AsMVT(mvt, 'reports', 4096, 'geom'), ''::bytea)::bytea AS tile
+FROM mvt;
\ No newline at end of file
@@ -0,0 +1,86 @@
+-- name: AssignUserArea :one
+-- No row is returned when the area does not exist. Assigning an area twice only updates assigned_by.
+INSERT INTO user_areas (
+    user_id,
+    area_id,
+    assigned_by
+)
+SELECT
+    @user_id,
+    a.id,
+    @assigned_by
+FROM areas a
+WHERE a.id = @area_id AND a.deleted_at IS NULL
+ON CONFLICT (user_id, area_id) DO UPDATE
+SET assigned_by = EXCLUDED.assigned_by
+RETURNING *;
+
+-- name: UnassignUserArea :one
+DELETE FROM user_areas
+WHERE user_id = @user_id AND area_id = @area_id
+RETURNING area_id;
+
+-- name: GetUserAreas :many
+SELECT
+    a.id,
+    a.name,
+    a.area_type,
+    a.area_code,
+    a.parent_id,
+    a.is_active,
+    ua.assigned_by,
+    ua.created_at
+FROM user_areas ua
+JOIN areas a ON ua.area_id = a.id
+WHERE ua.user_id = @user_id AND a.deleted_at IS NULL
+ORDER BY a.name;
+
+-- name: GetUserAreaTree :many
+-- The areas assigned to a user together with all of their descendants.
+WITH RECURSIVE tree AS (
+    SELECT ua.area_id AS id
+    FROM user_areas ua
+    WHERE ua.user_id = @user_id
+    UNION
+    SELECT a.id
+    FROM areas a
+    JOIN tree t ON a.parent_id = t.id
+    WHERE a.deleted_at IS NULL
+)
+SELECT id FROM tree;
+
+-- name: LockAssignments :exec
+-- Serializes picking an assignee until the transaction ends. Officials can cover several areas
+-- through their ancestors, so a lock per area would still let two reports pick the same official.
+SELECT pg_advisory_xact_lock(hashtext('report_assignment'));
+
+-- name: GetNextAssignee :one
+-- The official covering area_id (through it or one of its ancestors) who was least recently
+-- assigned a report, round-robin style. With user_id set, only that official can be returned.
+WITH RECURSIVE ancestors AS (
+    SELECT a.id, a.parent_id
+    FROM areas a
+    WHERE a.id = @area_id
+    UNION
+    SELECT p.id, p.parent_id
+    FROM areas p
+    JOIN ancestors an ON p.id = an.parent_id
+)
+SELECT
+    u.id,
+    u.username
+FROM users u
+JOIN roles ro ON u.role_id = ro.id
+WHERE ro.name = 'official'
+  AND ro.deleted_at IS NULL
+  AND u.deleted_at IS NULL
+  AND u.status <> 'suspended'
+  AND (sqlc.narg('user_id')::uuid IS NULL OR u.id = sqlc.narg('user_id')::uuid)
+  AND EXISTS (
+    SELECT 1
+    FROM user_areas ua
+    JOIN ancestors an ON ua.area_id = an.id
+    WHERE ua.user_id = u.id
+  )
+ORDER BY (SELECT MAX(r.assigned_at) FROM reports r WHERE r.assigned_to = u.id) ASC NULLS FIRST, u.id
+LIMIT 1;
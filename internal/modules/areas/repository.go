@@ -14,6 +14,7 @@ type AreaRepository interface {
 	GetAreas(arg db.GetAreasParams) ([]db.GetAreasRow, error)
 	GetAreaBoundary(id uuid.UUID) (db.GetAreaBoundaryRow, error)
 	ToggleAreaActiveStatus(id uuid.UUID) (db.ToggleAreaActiveStatusRow, error)
+	FindAreaByPoint(arg db.FindAreaByPointParams) (uuid.UUID, error)
 }
 
 type repository struct {
@@ -43,3 +44,7 @@ func (r *repository) GetAreaBoundary(id uuid.UUID) (db.GetAreaBoundaryRow, error
 func (r *repository) ToggleAreaActiveStatus(id uuid.UUID) (db.ToggleAreaActiveStatusRow, error) {
 	return r.db.ToggleAreaActiveStatus(context.Background(), id)
 }
+
+func (r *repository) FindAreaByPoint(arg db.FindAreaByPointParams) (uuid.UUID, error) {
+	return r.db.FindAreaByPoint(context.Background(), arg)
+}
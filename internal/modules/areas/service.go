@@ -20,6 +20,7 @@ type AreaService interface {
 	GetAreas(page, limit int, tolerance pkg.AreaTolerance) ([]db.GetAreasRow, error)
 	GetAreaBoundary(id uuid.UUID) (db.GetAreaBoundaryRow, error)
 	ToggleAreaActiveStatus(currentUserID uuid.UUID, id uuid.UUID) (db.ToggleAreaActiveStatusRow, error)
+	FindAreaByPoint(lat, lng float64) (uuid.UUID, error)
 }
 
 type service struct {
@@ -228,3 +229,12 @@ func (s *service) ToggleAreaActiveStatus(currentUserID uuid.UUID, id uuid.UUID)
 
 	return res, nil
 }
+
+// FindAreaByPoint returns the deepest active area whose boundary covers the
+// given coordinate. Returns pkg.ErrNoRows when the point is outside every area.
+func (s *service) FindAreaByPoint(lat, lng float64) (uuid.UUID, error) {
+	return s.repo.FindAreaByPoint(db.FindAreaByPointParams{
+		Lng: lng,
+		Lat: lat,
+	})
+}
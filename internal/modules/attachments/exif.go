@@ -0,0 +1,501 @@
+package attachments
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrMalformedImage = errors.New("malformed image")
+
+// photoMetadata is what we keep from a photo's EXIF before stripping it.
+type photoMetadata struct {
+	HasGPS      bool
+	Lat         float64
+	Lng         float64
+	CapturedAt  time.Time // zero when the photo has no capture time
+	Orientation int       // EXIF orientation (1-8), 0 when missing
+}
+
+const (
+	tagOrientation        = 0x0112
+	tagDateTime           = 0x0132
+	tagExifIFD            = 0x8769
+	tagGPSIFD             = 0x8825
+	tagDateTimeOriginal   = 0x9003
+	tagOffsetTimeOriginal = 0x9011
+
+	tagGPSLatitudeRef  = 0x0001
+	tagGPSLatitude     = 0x0002
+	tagGPSLongitudeRef = 0x0003
+	tagGPSLongitude    = 0x0004
+
+	exifDateLayout = "2006:01:02 15:04:05"
+)
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	exifHeader   = []byte("Exif\x00\x00")
+	iccHeader    = []byte("ICC_PROFILE\x00")
+)
+
+// readPhotoMetadata extracts GPS position, capture time and orientation from
+// the EXIF block of a JPEG, PNG or WebP image. Capture times without an
+// explicit offset are interpreted in loc.
+func readPhotoMetadata(data []byte, contentType string, loc *time.Location) (photoMetadata, bool) {
+	var raw []byte
+	switch contentType {
+	case "image/jpeg":
+		raw = jpegExif(data)
+	case "image/png":
+		raw = pngExif(data)
+	case "image/webp":
+		raw = webpExif(data)
+	}
+	if raw == nil {
+		return photoMetadata{}, false
+	}
+
+	t, ok := newTIFF(raw)
+	if !ok {
+		return photoMetadata{}, false
+	}
+
+	var meta photoMetadata
+
+	ifd0 := t.ifd(t.uint32(4))
+
+	if v, ok := ifd0[tagOrientation]; ok {
+		meta.Orientation = int(t.uint16(v.at))
+	}
+
+	dateTime := t.ascii(ifd0[tagDateTime])
+	offset := ""
+	if e, ok := ifd0[tagExifIFD]; ok {
+		exif := t.ifd(t.uint32(e.at))
+		if v := t.ascii(exif[tagDateTimeOriginal]); v != "" {
+			dateTime = v
+		}
+		offset = t.ascii(exif[tagOffsetTimeOriginal])
+	}
+	meta.CapturedAt = parseExifTime(dateTime, offset, loc)
+
+	if g, ok := ifd0[tagGPSIFD]; ok {
+		gps := t.ifd(t.uint32(g.at))
+		lat, latOK := t.coordinate(gps[tagGPSLatitude])
+		lng, lngOK := t.coordinate(gps[tagGPSLongitude])
+		if latOK && lngOK {
+			if t.ascii(gps[tagGPSLatitudeRef]) == "S" {
+				lat = -lat
+			}
+			if t.ascii(gps[tagGPSLongitudeRef]) == "W" {
+				lng = -lng
+			}
+			// 0,0 is what some cameras write when they had no fix
+			meta.HasGPS = lat != 0 || lng != 0
+			meta.Lat, meta.Lng = lat, lng
+		}
+	}
+
+	return meta, true
+}
+
+func parseExifTime(value, offset string, loc *time.Location) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+
+	if offset = strings.TrimSpace(offset); offset != "" {
+		if t, err := time.Parse(exifDateLayout+"-07:00", value+offset); err == nil {
+			return t
+		}
+	}
+
+	t, err := time.ParseInLocation(exifDateLayout, value, loc)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// stripMetadata returns a copy of the image without EXIF, XMP, IPTC or
+// comment blocks. Pixel data is copied verbatim, it is never re-encoded.
+func stripMetadata(data []byte, contentType string) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	}
+	return data, nil
+}
+
+// JPEG
+
+// jpegSegments calls fn for every marker segment before the image data.
+// fn receives the marker, the whole segment (marker and length included)
+// and its payload. It returns the offset of the start-of-scan marker.
+func jpegSegments(data []byte, fn func(marker byte, segment, payload []byte)) (int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0, ErrMalformedImage
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 0, ErrMalformedImage
+		}
+
+		marker := data[pos+1]
+		switch {
+		case marker == 0xFF:
+			// fill byte
+			pos++
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			return pos, nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// standalone markers without a length
+			pos += 2
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return 0, ErrMalformedImage
+		}
+
+		fn(marker, data[pos:end], data[pos+4:end])
+		pos = end
+	}
+
+	return 0, ErrMalformedImage
+}
+
+func jpegExif(data []byte) []byte {
+	var raw []byte
+	jpegSegments(data, func(marker byte, _, payload []byte) {
+		if raw == nil && marker == 0xE1 && bytes.HasPrefix(payload, exifHeader) {
+			raw = payload[len(exifHeader):]
+		}
+	})
+	return raw
+}
+
+func stripJPEG(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+
+	sos, err := jpegSegments(data, func(marker byte, segment, payload []byte) {
+		switch {
+		case marker == 0xE2 && bytes.HasPrefix(payload, iccHeader):
+			// keep the ICC color profile
+		case marker == 0xE0 || marker == 0xEE:
+			// JFIF and Adobe headers affect how the pixels are decoded
+		case marker >= 0xE1 && marker <= 0xEF, marker == 0xFE:
+			// APPn blocks (EXIF, XMP, IPTC, maker notes...) and comments
+			return
+		}
+		out = append(out, segment...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(out, data[sos:jpegImageEnd(data, sos)]...), nil
+}
+
+// jpegImageEnd returns the offset just past the end-of-image marker of the
+// image whose scans start at sos, or len(data) when the file is cut short.
+// What follows, like the secondary images of an MPF file or trailers added
+// by phone apps, carries its own metadata and is dropped.
+func jpegImageEnd(data []byte, sos int) int {
+	pos := sos
+	for pos+1 < len(data) {
+		if data[pos] != 0xFF {
+			pos++
+			continue
+		}
+
+		marker := data[pos+1]
+		switch {
+		case marker == 0xD9:
+			return pos + 2
+		case marker == 0xFF:
+			// fill byte
+			pos++
+		case marker == 0x00 || marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// stuffed 0xFF in the scan data, or a marker without a length
+			pos += 2
+		default:
+			// tables and further scans of a progressive image
+			if pos+4 > len(data) {
+				return len(data)
+			}
+			pos += 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		}
+	}
+
+	return len(data)
+}
+
+// PNG
+
+// pngChunks calls fn for every chunk, with the whole chunk (length, type,
+// data and CRC) and its data.
+func pngChunks(data []byte, fn func(kind string, chunk, payload []byte)) error {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return ErrMalformedImage
+	}
+
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return ErrMalformedImage
+		}
+
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return ErrMalformedImage
+		}
+
+		kind := string(data[pos+4 : pos+8])
+		fn(kind, data[pos:end], data[pos+8:end-4])
+		pos = end
+
+		if kind == "IEND" {
+			break
+		}
+	}
+
+	return nil
+}
+
+func pngExif(data []byte) []byte {
+	var raw []byte
+	pngChunks(data, func(kind string, _, payload []byte) {
+		if raw == nil && kind == "eXIf" {
+			raw = payload
+		}
+	})
+	return raw
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+
+	err := pngChunks(data, func(kind string, chunk, _ []byte) {
+		switch kind {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+			// EXIF, text metadata (XMP lives in iTXt) and modification time
+			return
+		}
+		out = append(out, chunk...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// WebP
+
+const (
+	vp8xFlagXMP  = 0x04
+	vp8xFlagEXIF = 0x08
+)
+
+// webpChunks calls fn for every RIFF chunk, with the whole chunk (header,
+// data and padding) and its data.
+func webpChunks(data []byte, fn func(fourCC string, chunk, payload []byte)) error {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return ErrMalformedImage
+	}
+
+	pos := 12
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return ErrMalformedImage
+		}
+
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2
+		if size < 0 || pos+8+size > len(data) {
+			return ErrMalformedImage
+		}
+		if end > len(data) {
+			// tolerate a missing padding byte on the last chunk
+			end = len(data)
+		}
+
+		fn(string(data[pos:pos+4]), data[pos:end], data[pos+8:pos+8+size])
+		pos = end
+	}
+
+	return nil
+}
+
+func webpExif(data []byte) []byte {
+	var raw []byte
+	webpChunks(data, func(fourCC string, _, payload []byte) {
+		if raw == nil && fourCC == "EXIF" {
+			// some encoders keep the JPEG style header
+			raw = bytes.TrimPrefix(payload, exifHeader)
+		}
+	})
+	return raw
+}
+
+func stripWebP(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+
+	err := webpChunks(data, func(fourCC string, chunk, payload []byte) {
+		switch fourCC {
+		case "EXIF", "XMP ":
+			return
+		case "VP8X":
+			// the extended header announces which metadata chunks follow
+			if len(payload) > 0 {
+				start := len(out)
+				out = append(out, chunk...)
+				out[start+8] &^= vp8xFlagEXIF | vp8xFlagXMP
+				return
+			}
+		}
+		out = append(out, chunk...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// TIFF (the EXIF container)
+
+type tiff struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// tiffEntry is an IFD entry; at is the offset of its value in the TIFF data.
+type tiffEntry struct {
+	kind  uint16
+	count uint32
+	at    uint32
+}
+
+var tiffTypeSizes = map[uint16]uint32{
+	1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 7: 1, 9: 4, 10: 8,
+}
+
+func newTIFF(data []byte) (*tiff, bool) {
+	if len(data) < 8 {
+		return nil, false
+	}
+
+	t := &tiff{data: data}
+	switch string(data[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return nil, false
+	}
+
+	if t.uint16(2) != 42 {
+		return nil, false
+	}
+
+	return t, true
+}
+
+func (t *tiff) uint16(at uint32) uint16 {
+	if uint64(at)+2 > uint64(len(t.data)) {
+		return 0
+	}
+	return t.order.Uint16(t.data[at:])
+}
+
+func (t *tiff) uint32(at uint32) uint32 {
+	if uint64(at)+4 > uint64(len(t.data)) {
+		return 0
+	}
+	return t.order.Uint32(t.data[at:])
+}
+
+// ifd reads the directory at offset into a map keyed by tag.
+func (t *tiff) ifd(offset uint32) map[uint16]tiffEntry {
+	entries := make(map[uint16]tiffEntry)
+	if offset == 0 || uint64(offset)+2 > uint64(len(t.data)) {
+		return entries
+	}
+
+	count := uint32(t.uint16(offset))
+	for i := uint32(0); i < count; i++ {
+		at := offset + 2 + i*12
+		if uint64(at)+12 > uint64(len(t.data)) {
+			break
+		}
+
+		entry := tiffEntry{
+			kind:  t.uint16(at + 2),
+			count: t.uint32(at + 4),
+			at:    at + 8,
+		}
+
+		// values larger than 4 bytes are stored elsewhere
+		size, ok := tiffTypeSizes[entry.kind]
+		if !ok {
+			continue
+		}
+		if uint64(size)*uint64(entry.count) > 4 {
+			entry.at = t.uint32(at + 8)
+		}
+		if uint64(entry.at)+uint64(size)*uint64(entry.count) > uint64(len(t.data)) {
+			continue
+		}
+
+		entries[t.uint16(at)] = entry
+	}
+
+	return entries
+}
+
+func (t *tiff) ascii(e tiffEntry) string {
+	if e.kind != 2 || e.count == 0 {
+		return ""
+	}
+	return strings.TrimRight(string(t.data[e.at:e.at+e.count]), "\x00 ")
+}
+
+// coordinate reads a degrees/minutes/seconds GPS value as decimal degrees.
+func (t *tiff) coordinate(e tiffEntry) (float64, bool) {
+	if e.kind != 5 || e.count != 3 {
+		return 0, false
+	}
+
+	var parts [3]float64
+	for i := range parts {
+		num := t.uint32(e.at + uint32(i)*8)
+		den := t.uint32(e.at + uint32(i)*8 + 4)
+		if den == 0 {
+			return 0, false
+		}
+		parts[i] = float64(num) / float64(den)
+	}
+
+	return parts[0] + parts[1]/60 + parts[2]/3600, true
+}
@@ -0,0 +1,25 @@
+package attachments
+
+const (
+	FileTypeImage    = "image"
+	FileTypeVideo    = "video"
+	FileTypeDocument = "document"
+
+	MB = 1 << 20
+)
+
+type fileRule struct {
+	FileType string
+	MaxSize  int64
+}
+
+// allowedTypes maps every accepted (sniffed) content type to the
+// file_type stored in report_attachments and its upload size limit.
+var allowedTypes = map[string]fileRule{
+	"image/jpeg":      {FileType: FileTypeImage, MaxSize: 10 * MB},
+	"image/png":       {FileType: FileTypeImage, MaxSize: 10 * MB},
+	"image/webp":      {FileType: FileTypeImage, MaxSize: 10 * MB},
+	"video/mp4":       {FileType: FileTypeVideo, MaxSize: 50 * MB},
+	"video/quicktime": {FileType: FileTypeVideo, MaxSize: 50 * MB},
+	"application/pdf": {FileType: FileTypeDocument, MaxSize: 10 * MB},
+}
@@ -0,0 +1,134 @@
+package attachments
+
+import (
+	"bytes"
+	"fmt"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"image"
+	"image/jpeg"
+	"math"
+	"time"
+)
+
+const earthRadiusMeters = 6371000
+
+// preparedPhoto is an uploaded image ready to be stored: metadata removed
+// and, for JPEG, rotated upright.
+type preparedPhoto struct {
+	Data     []byte
+	Metadata photoMetadata
+	HasMeta  bool
+}
+
+// preparePhoto reads the EXIF of an uploaded image and strips it. Once the
+// EXIF is gone viewers can no longer honour its orientation tag, so rotated
+// JPEGs are re-encoded upright.
+func preparePhoto(data []byte, contentType string, loc *time.Location) (preparedPhoto, error) {
+	meta, hasMeta := readPhotoMetadata(data, contentType, loc)
+
+	cleaned, err := stripMetadata(data, contentType)
+	if err != nil {
+		return preparedPhoto{}, err
+	}
+
+	if contentType == "image/jpeg" && meta.Orientation > 1 && meta.Orientation <= 8 {
+		// check the dimensions before decoding, a small file can hold a
+		// huge image
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(cleaned))
+		if err != nil {
+			return preparedPhoto{}, err
+		}
+		if cfg.Width*cfg.Height > maxImagePixels {
+			return preparedPhoto{}, ErrImageTooLarge
+		}
+
+		src, err := jpeg.Decode(bytes.NewReader(cleaned))
+		if err != nil {
+			return preparedPhoto{}, err
+		}
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, orient(src, meta.Orientation), &jpeg.Options{Quality: 90}); err != nil {
+			return preparedPhoto{}, err
+		}
+		cleaned = buf.Bytes()
+	}
+
+	return preparedPhoto{
+		Data:     cleaned,
+		Metadata: meta,
+		HasMeta:  hasMeta,
+	}, nil
+}
+
+// orient applies an EXIF orientation to src, returning an upright image.
+func orient(src image.Image, orientation int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = w-1-x, y
+			case 3: // rotated 180
+				sx, sy = w-1-x, h-1-y
+			case 4: // flipped vertically
+				sx, sy = x, h-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // rotated 90 clockwise
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotated 90 counter clockwise
+				sx, sy = w-1-y, x
+			default:
+				sx, sy = x, y
+			}
+			dst.Set(x, y, src.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+
+	return dst
+}
+
+// photoMismatch compares where and when a photo was taken with the report it
+// was attached to. It returns a human readable reason, or "" when it matches.
+func photoMismatch(meta photoMetadata, report db.GetReportByIDRow, maxDistance float64, maxAge time.Duration) string {
+	if meta.HasGPS {
+		distance := haversine(meta.Lat, meta.Lng, report.Lat, report.Lng)
+		if distance > maxDistance {
+			return fmt.Sprintf("photo was taken %.1f km from the report location", distance/1000)
+		}
+	}
+
+	if !meta.CapturedAt.IsZero() && report.CreatedAt.Valid {
+		age := report.CreatedAt.Time.Sub(meta.CapturedAt)
+		if age > maxAge {
+			return fmt.Sprintf("photo was taken %s before the report was submitted", age.Round(time.Hour))
+		}
+	}
+
+	return ""
+}
+
+// haversine returns the great-circle distance between two points in meters.
+func haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
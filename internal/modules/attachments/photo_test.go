@@ -0,0 +1,141 @@
+package attachments
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+	"time"
+)
+
+// testJPEG encodes a small solid image.
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 80, B: 40, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 80}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// exifSegment returns an APP1 segment holding an EXIF block with only the
+// orientation tag, plus the "secret" marker used to spot leaks.
+func exifSegment(orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1) // one entry
+	tiff = binary.BigEndian.AppendUint16(tiff, tagOrientation)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0)
+	tiff = binary.BigEndian.AppendUint32(tiff, 0) // no next IFD
+	tiff = append(tiff, "secret"...)
+
+	payload := append(append([]byte{}, exifHeader...), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// withSegment inserts segment right after the SOI marker of a JPEG.
+func withSegment(data, segment []byte) []byte {
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestStripJPEGDropsTrailingImages(t *testing.T) {
+	primary := withSegment(testJPEG(t, 16, 16), exifSegment(1))
+
+	// an MPF style secondary image with its own EXIF, then a vendor trailer
+	secondary := withSegment(testJPEG(t, 8, 8), exifSegment(1))
+	data := append(append(append([]byte{}, primary...), secondary...), "TRAILER secret"...)
+
+	stripped, err := stripJPEG(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(stripped, []byte("secret")) || bytes.Contains(stripped, exifHeader) {
+		t.Fatal("metadata of the trailing data survived stripping")
+	}
+	if !bytes.HasSuffix(stripped, []byte{0xFF, 0xD9}) {
+		t.Fatal("stripped image does not end at its EOI marker")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Fatalf("stripped image does not decode: %v", err)
+	}
+}
+
+func TestStripJPEGSkipsSegmentsAfterTheScan(t *testing.T) {
+	// a segment after the scan, like the tables between progressive scans,
+	// whose payload contains FF D9
+	base := testJPEG(t, 16, 16)
+	sos, err := jpegSegments(base, func(byte, []byte, []byte) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	end := jpegImageEnd(base, sos)
+
+	comment := []byte{0xFF, 0xFE, 0x00, 0x06, 0xFF, 0xD9, 0x00, 0x00}
+	data := append(append([]byte{}, base[:end-2]...), comment...)
+	data = append(data, 0xFF, 0xD9)
+
+	stripped, err := stripJPEG(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(stripped, append(comment, 0xFF, 0xD9)) {
+		t.Fatal("stopped at an FF D9 inside a segment after the first scan")
+	}
+}
+
+func TestPreparePhotoRejectsDecompressionBombs(t *testing.T) {
+	data := withSegment(testJPEG(t, 16, 16), exifSegment(6))
+
+	// claim 60000x60000 pixels in the frame header
+	sof := bytes.Index(data, []byte{0xFF, 0xC0})
+	if sof < 0 {
+		t.Fatal("no SOF0 segment")
+	}
+	binary.BigEndian.PutUint16(data[sof+5:], 60000)
+	binary.BigEndian.PutUint16(data[sof+7:], 60000)
+
+	if _, err := preparePhoto(data, "image/jpeg", time.UTC); !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("got %v, want ErrImageTooLarge", err)
+	}
+}
+
+func TestPreparePhotoRotates(t *testing.T) {
+	data := withSegment(testJPEG(t, 32, 16), exifSegment(6))
+
+	photo, err := preparePhoto(data, "image/jpeg", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if photo.Metadata.Orientation != 6 {
+		t.Fatalf("orientation %d", photo.Metadata.Orientation)
+	}
+
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(photo.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Width != 16 || cfg.Height != 32 {
+		t.Fatalf("rotated image is %dx%d, want 16x32", cfg.Width, cfg.Height)
+	}
+	if bytes.Contains(photo.Data, exifHeader) {
+		t.Fatal("EXIF survived")
+	}
+}
@@ -0,0 +1,60 @@
+package attachments
+
+import (
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AttachmentsRepository interface {
+	CreateAttachment(arg db.CreateReportAttachmentParams) (db.ReportAttachment, error)
+	GetAttachments(reportID uuid.UUID) ([]db.ReportAttachment, error)
+	GetAttachmentByID(id uuid.UUID) (db.ReportAttachment, error)
+	CountAttachments(reportID uuid.UUID) (int64, error)
+	DeleteAttachment(id uuid.UUID) error
+	UpdateBlurhash(arg db.UpdateReportAttachmentBlurhashParams) error
+	CreateThumbnail(arg db.CreateReportAttachmentThumbnailParams) (db.ReportAttachmentThumbnail, error)
+	GetThumbnails(attachmentIDs []uuid.UUID) ([]db.ReportAttachmentThumbnail, error)
+}
+
+type repository struct {
+	db *db.Queries
+}
+
+func NewAttachmentsRepository(pool *pgxpool.Pool) AttachmentsRepository {
+	return &repository{db: db.New(pool)}
+}
+
+func (r *repository) CreateAttachment(arg db.CreateReportAttachmentParams) (db.ReportAttachment, error) {
+	return r.db.CreateReportAttachment(context.Background(), arg)
+}
+
+func (r *repository) GetAttachments(reportID uuid.UUID) ([]db.ReportAttachment, error) {
+	return r.db.GetReportAttachments(context.Background(), reportID)
+}
+
+func (r *repository) GetAttachmentByID(id uuid.UUID) (db.ReportAttachment, error) {
+	return r.db.GetReportAttachmentByID(context.Background(), id)
+}
+
+func (r *repository) CountAttachments(reportID uuid.UUID) (int64, error) {
+	return r.db.CountReportAttachments(context.Background(), reportID)
+}
+
+func (r *repository) DeleteAttachment(id uuid.UUID) error {
+	return r.db.DeleteReportAttachment(context.Background(), id)
+}
+
+func (r *repository) UpdateBlurhash(arg db.UpdateReportAttachmentBlurhashParams) error {
+	return r.db.UpdateReportAttachmentBlurhash(context.Background(), arg)
+}
+
+func (r *repository) CreateThumbnail(arg db.CreateReportAttachmentThumbnailParams) (db.ReportAttachmentThumbnail, error) {
+	return r.db.CreateReportAttachmentThumbnail(context.Background(), arg)
+}
+
+func (r *repository) GetThumbnails(attachmentIDs []uuid.UUID) ([]db.ReportAttachmentThumbnail, error) {
+	return r.db.GetReportAttachmentThumbnails(context.Background(), attachmentIDs)
+}
@@ -0,0 +1,514 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/internal/modules/auditlogs"
+	"hubku/lapor_warga_be_v2/internal/modules/reports"
+	"hubku/lapor_warga_be_v2/pkg"
+	"io"
+	"log"
+	"mime/multipart"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/spf13/viper"
+)
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrNoFiles            = errors.New("no files uploaded")
+	ErrUnsupportedType    = errors.New("unsupported file type")
+	ErrFileTooLarge       = errors.New("file is too large")
+	ErrTooManyFiles       = errors.New("too many attachments for this report")
+	ErrForbidden          = errors.New("not allowed to modify attachments of this report")
+	ErrInvalidImage       = errors.New("invalid image")
+)
+
+// Attachment is a report attachment along with its generated thumbnails.
+type Attachment struct {
+	db.ReportAttachment
+	Thumbnails []db.ReportAttachmentThumbnail `json:"thumbnails"`
+}
+
+type AttachmentsService interface {
+	UploadAttachments(actor reports.Actor, reportID uuid.UUID, files []*multipart.FileHeader) ([]Attachment, error)
+	GetAttachments(currentUserID uuid.UUID, role string, reportID uuid.UUID) ([]Attachment, error)
+	DeleteAttachment(actor reports.Actor, id uuid.UUID) error
+}
+
+type service struct {
+	repo          AttachmentsRepository
+	storage       Storage
+	reportService reports.ReportsService
+	logService    auditlogs.LogsService
+	maxPerReport  int64
+	urlTTL        time.Duration
+
+	// photo cross-check against the report
+	maxPhotoDistance float64
+	maxPhotoAge      time.Duration
+	photoLocation    *time.Location
+}
+
+func NewAttachmentsService(
+	repo AttachmentsRepository,
+	storage Storage,
+	reportService reports.ReportsService,
+	logService auditlogs.LogsService,
+) AttachmentsService {
+	viper.SetDefault("ATTACHMENT_MAX_PER_REPORT", 5)
+	viper.SetDefault("PHOTO_MAX_DISTANCE_METERS", 1000)
+	viper.SetDefault("PHOTO_MAX_AGE_HOURS", 72)
+	viper.SetDefault("PHOTO_TIMEZONE", "Asia/Jakarta")
+	viper.SetDefault("ATTACHMENT_URL_TTL", "1h")
+
+	// EXIF capture times usually carry no offset, assume the local time of our users
+	loc, err := time.LoadLocation(viper.GetString("PHOTO_TIMEZONE"))
+	if err != nil {
+		log.Printf("invalid PHOTO_TIMEZONE, falling back to UTC: %v", err)
+		loc = time.UTC
+	}
+
+	return &service{
+		repo:             repo,
+		storage:          storage,
+		reportService:    reportService,
+		logService:       logService,
+		maxPerReport:     viper.GetInt64("ATTACHMENT_MAX_PER_REPORT"),
+		urlTTL:           viper.GetDuration("ATTACHMENT_URL_TTL"),
+		maxPhotoDistance: viper.GetFloat64("PHOTO_MAX_DISTANCE_METERS"),
+		maxPhotoAge:      time.Duration(viper.GetInt64("PHOTO_MAX_AGE_HOURS")) * time.Hour,
+		photoLocation:    loc,
+	}
+}
+
+// upload is a validated file waiting to be written to storage.
+type upload struct {
+	header      *multipart.FileHeader
+	contentType string
+	extension   string
+	rule        fileRule
+
+	// images are held in memory with their metadata already stripped
+	photo *preparedPhoto
+}
+
+func (s *service) UploadAttachments(actor reports.Actor, reportID uuid.UUID, files []*multipart.FileHeader) ([]Attachment, error) {
+	if len(files) == 0 {
+		return nil, ErrNoFiles
+	}
+
+	report, err := s.checkAccess(actor, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountAttachments(reportID)
+	if err != nil {
+		return nil, err
+	}
+	if count+int64(len(files)) > s.maxPerReport {
+		return nil, ErrTooManyFiles
+	}
+
+	// validate every file before writing anything, so a bad file
+	// doesn't leave the report with half of the batch attached
+	uploads := make([]upload, 0, len(files))
+	for _, fh := range files {
+		u, err := sniff(fh)
+		if err != nil {
+			return nil, err
+		}
+
+		if u.rule.FileType == FileTypeImage {
+			if err := s.preparePhoto(&u); err != nil {
+				return nil, err
+			}
+		}
+
+		uploads = append(uploads, u)
+	}
+
+	result := make([]Attachment, 0, len(uploads))
+	for _, u := range uploads {
+		attachment, err := s.store(reportID, u)
+		if err != nil {
+			s.cleanup(result)
+			return nil, err
+		}
+		result = append(result, attachment)
+	}
+
+	// flag the report when a photo was taken somewhere else, or long before
+	mismatch := ""
+	for _, u := range uploads {
+		if u.photo == nil || !u.photo.HasMeta {
+			continue
+		}
+		if mismatch = photoMismatch(u.photo.Metadata, report, s.maxPhotoDistance, s.maxPhotoAge); mismatch != "" {
+			break
+		}
+	}
+
+	if mismatch != "" {
+		if err := s.reportService.FlagLocationMismatch(reportID, mismatch); err != nil {
+			log.Printf("failed to flag location mismatch on report %s: %v", reportID, err)
+		}
+	}
+
+	// log upload attachments
+	go func() {
+		ids := make([]string, 0, len(result))
+		for _, a := range result {
+			ids = append(ids, a.ID.String())
+		}
+
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"attachment_ids":    ids,
+			"location_mismatch": mismatch,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityAttachments),
+			Action:      string(pkg.LogTypeCreate),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    reportID,
+			PerformedBy: actor.ID,
+		})
+	}()
+
+	if err := s.signURLs(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *service) GetAttachments(currentUserID uuid.UUID, role string, reportID uuid.UUID) ([]Attachment, error) {
+	// attachments follow the visibility rules of their report
+	if _, err := s.reportService.GetReportByID(currentUserID, role, reportID); err != nil {
+		return nil, err
+	}
+
+	list, err := s.repo.GetAttachments(reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(list))
+	for _, a := range list {
+		ids = append(ids, a.ID)
+	}
+
+	thumbnails, err := s.repo.GetThumbnails(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byAttachment := make(map[uuid.UUID][]db.ReportAttachmentThumbnail)
+	for _, t := range thumbnails {
+		byAttachment[t.AttachmentID] = append(byAttachment[t.AttachmentID], t)
+	}
+
+	result := make([]Attachment, 0, len(list))
+	for _, a := range list {
+		result = append(result, Attachment{
+			ReportAttachment: a,
+			Thumbnails:       append([]db.ReportAttachmentThumbnail{}, byAttachment[a.ID]...),
+		})
+	}
+
+	if err := s.signURLs(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// signURLs replaces the stored URLs of attachments and their thumbnails
+// with signed ones, so files are only reachable by users who can see the
+// report. URLs are signed from the start of the current half-TTL window, so
+// they stay the same for a while and clients can cache the files.
+func (s *service) signURLs(list []Attachment) error {
+	ttl := s.urlTTL
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	from := time.Now().Truncate(ttl / 2)
+
+	sign := func(stored string) (string, error) {
+		key, ok := s.storage.Key(stored)
+		if !ok {
+			return stored, nil
+		}
+		return s.storage.SignedURL(key, from, ttl)
+	}
+
+	for i := range list {
+		url, err := sign(list[i].FileUrl)
+		if err != nil {
+			return err
+		}
+		list[i].FileUrl = url
+
+		for j := range list[i].Thumbnails {
+			url, err := sign(list[i].Thumbnails[j].FileUrl)
+			if err != nil {
+				return err
+			}
+			list[i].Thumbnails[j].FileUrl = url
+		}
+	}
+
+	return nil
+}
+
+func (s *service) DeleteAttachment(actor reports.Actor, id uuid.UUID) error {
+	attachment, err := s.repo.GetAttachmentByID(id)
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return ErrAttachmentNotFound
+		}
+		return err
+	}
+
+	if _, err := s.checkAccess(actor, attachment.ReportID); err != nil {
+		return err
+	}
+
+	// thumbnail rows are removed by the cascade, but their files are not
+	thumbnails, err := s.repo.GetThumbnails([]uuid.UUID{id})
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteAttachment(id); err != nil {
+		return err
+	}
+
+	s.removeObject(attachment.FileUrl)
+	for _, t := range thumbnails {
+		s.removeObject(t.FileUrl)
+	}
+
+	// log delete attachment
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"report_id": attachment.ReportID,
+			"file_url":  attachment.FileUrl,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityAttachments),
+			Action:      string(pkg.LogTypeDelete),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    id,
+			PerformedBy: actor.ID,
+		})
+	}()
+
+	return nil
+}
+
+// checkAccess allows officials and admins on any report they can see,
+// and citizens only on their own reports.
+func (s *service) checkAccess(actor reports.Actor, reportID uuid.UUID) (db.GetReportByIDRow, error) {
+	report, err := s.reportService.GetReportByID(actor.ID, string(actor.Role), reportID)
+	if err != nil {
+		return db.GetReportByIDRow{}, err
+	}
+
+	if actor.Role == pkg.RoleCitizen && report.UserID != actor.ID {
+		return db.GetReportByIDRow{}, ErrForbidden
+	}
+
+	return report, nil
+}
+
+// preparePhoto loads an image upload into memory, reads its EXIF and strips
+// it, so no location or device data is ever written to storage.
+func (s *service) preparePhoto(u *upload) error {
+	file, err := u.header.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	photo, err := preparePhoto(data, u.contentType, s.photoLocation)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidImage, u.header.Filename)
+	}
+
+	u.photo = &photo
+	return nil
+}
+
+func (s *service) store(reportID uuid.UUID, u upload) (Attachment, error) {
+	var (
+		body io.Reader
+		size = u.header.Size
+	)
+
+	if u.photo != nil {
+		body = bytes.NewReader(u.photo.Data)
+		size = int64(len(u.photo.Data))
+	} else {
+		file, err := u.header.Open()
+		if err != nil {
+			return Attachment{}, err
+		}
+		defer file.Close()
+		body = file
+	}
+
+	name := fmt.Sprintf("reports/%s/%s", reportID, uuid.New())
+	key := name + u.extension
+
+	url, err := s.storage.Put(context.Background(), key, body, size, u.contentType)
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	attachment, err := s.repo.CreateAttachment(db.CreateReportAttachmentParams{
+		ReportID: reportID,
+		FileUrl:  url,
+		FileType: u.rule.FileType,
+		FileSize: pgtype.Int8{
+			Int64: size,
+			Valid: true,
+		},
+	})
+	if err != nil {
+		s.removeObject(url)
+		return Attachment{}, err
+	}
+
+	result := Attachment{
+		ReportAttachment: attachment,
+		Thumbnails:       []db.ReportAttachmentThumbnail{},
+	}
+
+	if u.photo != nil {
+		// the original is already stored, a failure here only means the
+		// clients fall back to the full-size image
+		if err := s.storeThumbnails(name, u, &result); err != nil {
+			log.Printf("failed to generate thumbnails for attachment %s: %v", attachment.ID, err)
+		}
+	}
+
+	return result, nil
+}
+
+// storeThumbnails renders the thumbnails and blurhash of an image attachment
+// and saves them next to the original, under "<name>_<size>.jpg".
+func (s *service) storeThumbnails(name string, u upload, attachment *Attachment) error {
+	processed, err := processImage(bytes.NewReader(u.photo.Data))
+	if err != nil {
+		return err
+	}
+
+	for _, t := range processed.Thumbnails {
+		url, err := s.storage.Put(
+			context.Background(),
+			fmt.Sprintf("%s_%s.jpg", name, t.Name),
+			bytes.NewReader(t.Data),
+			int64(len(t.Data)),
+			"image/jpeg",
+		)
+		if err != nil {
+			return err
+		}
+
+		thumbnail, err := s.repo.CreateThumbnail(db.CreateReportAttachmentThumbnailParams{
+			AttachmentID: attachment.ID,
+			Size:         t.Name,
+			Width:        int32(t.Width),
+			Height:       int32(t.Height),
+			FileUrl:      url,
+		})
+		if err != nil {
+			s.removeObject(url)
+			return err
+		}
+
+		attachment.Thumbnails = append(attachment.Thumbnails, thumbnail)
+	}
+
+	blurhash := pgtype.Text{String: processed.Blurhash, Valid: true}
+	if err := s.repo.UpdateBlurhash(db.UpdateReportAttachmentBlurhashParams{
+		Blurhash: blurhash,
+		ID:       attachment.ID,
+	}); err != nil {
+		return err
+	}
+	attachment.Blurhash = blurhash
+
+	return nil
+}
+
+// cleanup removes attachments stored earlier in a batch that failed halfway.
+func (s *service) cleanup(stored []Attachment) {
+	for _, a := range stored {
+		if err := s.repo.DeleteAttachment(a.ID); err != nil {
+			log.Printf("failed to delete attachment %s: %v", a.ID, err)
+		}
+		s.removeObject(a.FileUrl)
+		for _, t := range a.Thumbnails {
+			s.removeObject(t.FileUrl)
+		}
+	}
+}
+
+func (s *service) removeObject(url string) {
+	key, ok := s.storage.Key(url)
+	if !ok {
+		return
+	}
+
+	if err := s.storage.Delete(context.Background(), key); err != nil {
+		log.Printf("failed to delete object %s: %v", key, err)
+	}
+}
+
+// sniff detects the real content type of fh from its bytes, ignoring the
+// client-provided Content-Type and file name, and checks it against allowedTypes.
+func sniff(fh *multipart.FileHeader) (upload, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return upload{}, err
+	}
+	defer file.Close()
+
+	mt, err := mimetype.DetectReader(file)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return upload{}, err
+	}
+
+	rule, ok := allowedTypes[mt.String()]
+	if !ok {
+		return upload{}, fmt.Errorf("%w: %s (%s)", ErrUnsupportedType, fh.Filename, mt.String())
+	}
+
+	if fh.Size > rule.MaxSize {
+		return upload{}, fmt.Errorf("%w: %s exceeds %d MB", ErrFileTooLarge, fh.Filename, rule.MaxSize/MB)
+	}
+
+	return upload{
+		header:      fh,
+		contentType: mt.String(),
+		extension:   mt.Extension(),
+		rule:        rule,
+	}, nil
+}
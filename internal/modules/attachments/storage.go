@@ -0,0 +1,65 @@
+package attachments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Storage is where uploaded attachment files are written to.
+type Storage interface {
+	// Put stores body under key and returns the public URL of the object.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error)
+	// Delete removes the object stored under key.
+	Delete(ctx context.Context, key string) error
+	// Key returns the object key for a URL previously returned by Put.
+	Key(url string) (string, bool)
+	// SignedURL returns a URL that serves the object under key for ttl
+	// starting at from. Objects are not reachable without one.
+	SignedURL(key string, from time.Time, ttl time.Duration) (string, error)
+}
+
+// NewStorage builds the storage backend selected by STORAGE_DRIVER ("local" or "s3").
+func NewStorage() (Storage, error) {
+	viper.SetDefault("STORAGE_DRIVER", "local")
+
+	switch driver := viper.GetString("STORAGE_DRIVER"); driver {
+	case "local":
+		viper.SetDefault("UPLOAD_DIR", "./uploads")
+		viper.SetDefault("UPLOAD_BASE_URL", "/uploads")
+
+		return NewLocalStorage(
+			viper.GetString("UPLOAD_DIR"),
+			viper.GetString("UPLOAD_BASE_URL"),
+			urlSigningKey(viper.GetString("ENC_KEY")),
+		)
+	case "s3":
+		viper.SetDefault("S3_REGION", "us-east-1")
+		viper.SetDefault("S3_USE_PATH_STYLE", true)
+
+		return NewS3Storage(S3Config{
+			Endpoint:     viper.GetString("S3_ENDPOINT"),
+			Region:       viper.GetString("S3_REGION"),
+			Bucket:       viper.GetString("S3_BUCKET"),
+			AccessKey:    viper.GetString("S3_ACCESS_KEY"),
+			SecretKey:    viper.GetString("S3_SECRET_KEY"),
+			PublicURL:    viper.GetString("S3_PUBLIC_URL"),
+			UsePathStyle: viper.GetBool("S3_USE_PATH_STYLE"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", driver)
+	}
+}
+
+// urlSigningKey derives the key of signed upload URLs from the encryption
+// key, so the encryption key itself never signs anything public.
+func urlSigningKey(encKey string) []byte {
+	mac := hmac.New(sha256.New, []byte(encKey))
+	mac.Write([]byte("upload-urls"))
+	return mac.Sum(nil)
+}
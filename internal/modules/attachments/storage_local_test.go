@@ -0,0 +1,76 @@
+package attachments
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalStorageSignedURL(t *testing.T) {
+	storage, err := NewLocalStorage(t.TempDir(), "/uploads", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "reports/1/photo.jpg"
+	stored, err := storage.Put(context.Background(), key, strings.NewReader("jpeg"), 4, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != "/uploads/"+key {
+		t.Fatalf("stored url %q", stored)
+	}
+
+	now := time.Unix(1_700_000_000, 0)
+	signed, err := storage.SignedURL(key, now, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/uploads/"+key {
+		t.Fatalf("signed url path %q", u.Path)
+	}
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+	path, err := storage.Open(key, expires, signature, now.Add(59*time.Minute))
+	if err != nil {
+		t.Fatalf("open within the lifetime: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "jpeg" {
+		t.Fatalf("opened %q", data)
+	}
+
+	for name, tc := range map[string]struct {
+		key, expires, signature string
+		at                      time.Time
+	}{
+		"expired":       {key, expires, signature, now.Add(61 * time.Minute)},
+		"other key":     {"reports/2/photo.jpg", expires, signature, now},
+		"extended":      {key, "9999999999", signature, now},
+		"bad signature": {key, expires, strings.Repeat("0", len(signature)), now},
+		"no signature":  {key, "", "", now},
+	} {
+		if _, err := storage.Open(tc.key, tc.expires, tc.signature, tc.at); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: got %v, want ErrInvalidSignature", name, err)
+		}
+	}
+}
+
+func TestLocalStorageRejectsEscapingKeys(t *testing.T) {
+	storage, err := NewLocalStorage(t.TempDir(), "/uploads", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.SignedURL("../etc/passwd", time.Now(), time.Hour); err == nil {
+		t.Fatal("signed a key outside the upload directory")
+	}
+}
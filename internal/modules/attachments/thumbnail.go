@@ -0,0 +1,125 @@
+package attachments
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+
+	// register decoders for image.Decode
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// thumbnailSizes are the generated thumbnails, keyed by name, bounded by
+// their longest edge in pixels. Sizes larger than the original are skipped.
+var thumbnailSizes = []struct {
+	Name    string
+	MaxEdge int
+}{
+	{Name: "small", MaxEdge: 160},
+	{Name: "medium", MaxEdge: 480},
+	{Name: "large", MaxEdge: 1080},
+}
+
+const (
+	thumbnailQuality = 80
+
+	// blurhashEdge is the size images are shrunk to before computing the
+	// blurhash, the hash only keeps a handful of components anyway.
+	blurhashEdge = 32
+
+	// maxImagePixels guards against decompression bombs, a small file
+	// that claims to be an enormous image.
+	maxImagePixels = 50_000_000
+)
+
+var ErrImageTooLarge = errors.New("image dimensions are too large")
+
+type thumbnail struct {
+	Name   string
+	Width  int
+	Height int
+	Data   []byte
+}
+
+type processedImage struct {
+	Blurhash   string
+	Thumbnails []thumbnail
+}
+
+// processImage decodes an uploaded image and renders its thumbnails and blurhash.
+func processImage(r io.ReadSeeker) (processedImage, error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return processedImage{}, err
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return processedImage{}, ErrImageTooLarge
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return processedImage{}, err
+	}
+
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return processedImage{}, err
+	}
+
+	var result processedImage
+
+	for _, size := range thumbnailSizes {
+		width, height, ok := fit(src.Bounds(), size.MaxEdge)
+		if !ok {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resize(src, width, height, draw.CatmullRom), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+			return processedImage{}, err
+		}
+
+		result.Thumbnails = append(result.Thumbnails, thumbnail{
+			Name:   size.Name,
+			Width:  width,
+			Height: height,
+			Data:   buf.Bytes(),
+		})
+	}
+
+	width, height, ok := fit(src.Bounds(), blurhashEdge)
+	if !ok {
+		width, height = src.Bounds().Dx(), src.Bounds().Dy()
+	}
+	result.Blurhash = blurhash(resize(src, width, height, draw.ApproxBiLinear), 4, 3)
+
+	return result, nil
+}
+
+// fit scales bounds down so the longest edge is maxEdge, keeping the aspect ratio.
+// It returns false when the image is already that small.
+func fit(bounds image.Rectangle, maxEdge int) (int, int, bool) {
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxEdge && height <= maxEdge {
+		return width, height, false
+	}
+
+	if width >= height {
+		return maxEdge, max(1, height*maxEdge/width), true
+	}
+	return max(1, width*maxEdge/height), maxEdge, true
+}
+
+// resize draws src onto a white RGBA canvas of the given size, so transparent
+// PNG/WebP pixels don't turn black once encoded as JPEG.
+func resize(src image.Image, width, height int, scaler draw.Scaler) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	scaler.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+	return dst
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/spf13/viper"
 )
 
+var ErrAccountSuspended = errors.New("account is suspended")
+
 type AuthService interface {
 	Login(req LoginRequest, isMobile bool) (*LoginResponse, error)
 	ValidateToken(tokenString string) (*Claims, error)
@@ -78,6 +80,10 @@ func (s *service) Login(req LoginRequest, isMobile bool) (*LoginResponse, error)
 		return nil, errors.New("invalid credentials")
 	}
 
+	if user.Status.String == string(pkg.UserSuspended) {
+		return nil, ErrAccountSuspended
+	}
+
 	accessToken, err := s.GenerateToken(user)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
@@ -195,6 +201,10 @@ func (s *service) RefreshToken(req RefreshRequest) (*LoginResponse, error) {
 		return nil, errors.New("user not found")
 	}
 
+	if user.Status.String == string(pkg.UserSuspended) {
+		return nil, ErrAccountSuspended
+	}
+
 	accessToken, err := s.GenerateToken(user)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,147 @@
+package calendar
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNoWorkingTime = errors.New("calendar has no working hours")
+
+// maxSearchDays bounds the walk over the calendar, so a calendar whose
+// working days are all holidays cannot loop forever.
+const maxSearchDays = 5 * 366
+
+// Shift is a span of working time within a day, as offsets from midnight.
+type Shift struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Calendar is a weekly schedule of working hours with holidays, in the time
+// zone of the area it belongs to.
+type Calendar struct {
+	Location *time.Location
+	// Hours are the shifts of each weekday, indexed by time.Weekday.
+	Hours [7][]Shift
+	// Holidays are dates (YYYY-MM-DD) without working time.
+	Holidays map[string]string
+}
+
+// Deadline is when a span of business time that started at some point runs
+// out, and how much of it is left.
+type Deadline struct {
+	DueAt time.Time `json:"due_at"`
+	// RemainingSeconds is business time left, negative once overdue.
+	RemainingSeconds int64 `json:"remaining_seconds"`
+}
+
+// Deadline returns when d of business time counted from start runs out, and
+// how much business time is left at now.
+func (c Calendar) Deadline(start time.Time, d time.Duration, now time.Time) (Deadline, error) {
+	due, err := c.Add(start, d)
+	if err != nil {
+		return Deadline{}, err
+	}
+
+	remaining := c.Elapsed(now, due)
+	if now.After(due) {
+		remaining = -c.Elapsed(due, now)
+	}
+
+	return Deadline{DueAt: due, RemainingSeconds: int64(remaining / time.Second)}, nil
+}
+
+// Add returns the moment d of business time after start. Time outside
+// working hours and on holidays does not count.
+func (c Calendar) Add(start time.Time, d time.Duration) (time.Time, error) {
+	if !c.hasWorkingTime() {
+		return time.Time{}, ErrNoWorkingTime
+	}
+
+	cursor := start.In(c.location())
+	if d <= 0 {
+		return cursor, nil
+	}
+
+	day := midnight(cursor)
+	for i := 0; i < maxSearchDays; i++ {
+		for _, shift := range c.shifts(day) {
+			from, to := day.Add(shift.Start), day.Add(shift.End)
+			if to.Before(cursor) || to.Equal(cursor) {
+				continue
+			}
+			if from.Before(cursor) {
+				from = cursor
+			}
+
+			available := to.Sub(from)
+			if d <= available {
+				return from.Add(d), nil
+			}
+			d -= available
+		}
+		day = nextDay(day)
+	}
+
+	return time.Time{}, ErrNoWorkingTime
+}
+
+// Elapsed returns the business time between from and to, zero when to is not
+// after from.
+func (c Calendar) Elapsed(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+
+	from, to = from.In(c.location()), to.In(c.location())
+
+	var total time.Duration
+	for day := midnight(from); day.Before(to); day = nextDay(day) {
+		for _, shift := range c.shifts(day) {
+			start, end := day.Add(shift.Start), day.Add(shift.End)
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				total += end.Sub(start)
+			}
+		}
+	}
+
+	return total
+}
+
+// shifts returns the working shifts of day, none on holidays.
+func (c Calendar) shifts(day time.Time) []Shift {
+	if _, ok := c.Holidays[day.Format(time.DateOnly)]; ok {
+		return nil
+	}
+	return c.Hours[day.Weekday()]
+}
+
+func (c Calendar) hasWorkingTime() bool {
+	for _, shifts := range c.Hours {
+		if len(shifts) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Calendar) location() *time.Location {
+	if c.Location == nil {
+		return time.UTC
+	}
+	return c.Location
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func nextDay(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location())
+}
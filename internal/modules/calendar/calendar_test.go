@@ -0,0 +1,123 @@
+package calendar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var wib = time.FixedZone("WIB", 7*60*60)
+
+// testCalendar works Monday to Friday 08:00-16:00, plus a night shift from
+// Saturday 20:00 to Sunday 02:00. Monday 8 January 2024 is a holiday.
+func testCalendar() Calendar {
+	office := []Shift{{Start: 8 * time.Hour, End: 16 * time.Hour}}
+
+	var hours [7][]Shift
+	for day := time.Monday; day <= time.Friday; day++ {
+		hours[day] = office
+	}
+	hours[time.Saturday] = []Shift{{Start: 20 * time.Hour, End: 24 * time.Hour}}
+	hours[time.Sunday] = []Shift{{Start: 0, End: 2 * time.Hour}}
+
+	return Calendar{
+		Location: wib,
+		Hours:    hours,
+		Holidays: map[string]string{"2024-01-08": "Cuti bersama"},
+	}
+}
+
+func at(day, hour, minute int) time.Time {
+	return time.Date(2024, time.January, day, hour, minute, 0, 0, wib)
+}
+
+func TestCalendarAdd(t *testing.T) {
+	cal := testCalendar()
+
+	for name, tc := range map[string]struct {
+		start time.Time
+		d     time.Duration
+		want  time.Time
+	}{
+		"within a shift":        {at(1, 9, 0), 2 * time.Hour, at(1, 11, 0)},
+		"into the next day":     {at(1, 15, 0), 2 * time.Hour, at(2, 9, 0)},
+		"ends with the shift":   {at(1, 15, 0), time.Hour, at(1, 16, 0)},
+		"before working hours":  {at(1, 6, 0), time.Hour, at(1, 9, 0)},
+		"after working hours":   {at(5, 17, 0), time.Hour, at(6, 21, 0)},
+		"until 24:00":           {at(5, 17, 0), 4 * time.Hour, at(7, 0, 0)},
+		"across midnight":       {at(5, 17, 0), 5 * time.Hour, at(7, 1, 0)},
+		"over a holiday":        {at(7, 1, 0), 2 * time.Hour, at(9, 9, 0)},
+		"starting on a holiday": {at(8, 10, 0), 30 * time.Minute, at(9, 8, 30)},
+		"zero duration":         {at(6, 3, 0), 0, at(6, 3, 0)},
+		"start in another zone": {time.Date(2024, time.January, 1, 1, 0, 0, 0, time.UTC), time.Hour, at(1, 9, 0)},
+	} {
+		got, err := cal.Add(tc.start, tc.d)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("%s: got %s, want %s", name, got, tc.want)
+		}
+	}
+}
+
+func TestCalendarAddWithoutWorkingTime(t *testing.T) {
+	if _, err := (Calendar{Location: wib}).Add(at(1, 9, 0), time.Hour); !errors.Is(err, ErrNoWorkingTime) {
+		t.Fatalf("got %v, want ErrNoWorkingTime", err)
+	}
+}
+
+func TestCalendarElapsed(t *testing.T) {
+	cal := testCalendar()
+
+	for name, tc := range map[string]struct {
+		from, to time.Time
+		want     time.Duration
+	}{
+		"within a shift":      {at(1, 9, 0), at(1, 10, 30), 90 * time.Minute},
+		"outside any shift":   {at(1, 17, 0), at(2, 7, 0), 0},
+		"weekend and holiday": {at(5, 15, 0), at(9, 9, 0), 8 * time.Hour},
+		"backwards":           {at(2, 9, 0), at(1, 9, 0), 0},
+	} {
+		if got := cal.Elapsed(tc.from, tc.to); got != tc.want {
+			t.Errorf("%s: got %s, want %s", name, got, tc.want)
+		}
+	}
+}
+
+func TestCalendarAddThenElapsed(t *testing.T) {
+	cal := testCalendar()
+
+	for _, start := range []time.Time{at(1, 7, 0), at(3, 12, 0), at(5, 16, 0), at(6, 23, 0), at(8, 12, 0)} {
+		for _, d := range []time.Duration{time.Minute, 4 * time.Hour, 24 * time.Hour, 100 * time.Hour} {
+			due, err := cal.Add(start, d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cal.Elapsed(start, due); got != d {
+				t.Errorf("%s from %s: elapsed until %s is %s", d, start, due, got)
+			}
+		}
+	}
+}
+
+func TestCalendarDeadline(t *testing.T) {
+	cal := testCalendar()
+
+	deadline, err := cal.Deadline(at(1, 9, 0), 8*time.Hour, at(2, 8, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !deadline.DueAt.Equal(at(2, 9, 0)) || deadline.RemainingSeconds != 3600 {
+		t.Fatalf("got %+v", deadline)
+	}
+
+	deadline, err = cal.Deadline(at(1, 9, 0), 8*time.Hour, at(2, 11, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deadline.RemainingSeconds != -7200 {
+		t.Fatalf("overdue by %d seconds, want -7200", deadline.RemainingSeconds)
+	}
+}
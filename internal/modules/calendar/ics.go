@@ -0,0 +1,174 @@
+package calendar
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrInvalidICS = errors.New("invalid iCalendar file")
+
+// maxEventDays caps how many days a single event can mark as holidays.
+const maxEventDays = 31
+
+// holiday is one day off read from an iCalendar file.
+type holiday struct {
+	Date time.Time
+	Name string
+}
+
+// parseICS reads the all-day and timed events of an iCalendar (RFC 5545)
+// file as holidays, one per day the event covers. Dates with a time are
+// taken in loc. Recurrence rules are not expanded.
+func parseICS(r io.Reader, loc *time.Location) ([]holiday, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		holidays []holiday
+		inEvent  bool
+		calendar bool
+		start    time.Time
+		end      time.Time
+		summary  string
+	)
+
+	for _, line := range lines {
+		name, params, value, ok := splitProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && value == "VCALENDAR":
+			calendar = true
+		case name == "BEGIN" && value == "VEVENT":
+			inEvent = true
+			start, end, summary = time.Time{}, time.Time{}, ""
+		case name == "END" && value == "VEVENT":
+			if !inEvent || start.IsZero() {
+				return nil, ErrInvalidICS
+			}
+			inEvent = false
+
+			// DTEND is exclusive, an event without one lasts a day. An event
+			// ending during a day still covers it.
+			if !end.Equal(midnight(end)) {
+				end = nextDay(midnight(end))
+			}
+			start = midnight(start)
+
+			days := 1
+			if !end.IsZero() && end.After(start) {
+				days = int(end.Sub(start).Hours()/24 + 0.5)
+			}
+			if days > maxEventDays {
+				days = maxEventDays
+			}
+
+			for i := 0; i < days; i++ {
+				holidays = append(holidays, holiday{
+					Date: start.AddDate(0, 0, i),
+					Name: summary,
+				})
+			}
+		case inEvent && name == "DTSTART":
+			if start, err = parseICSDate(value, params, loc); err != nil {
+				return nil, err
+			}
+		case inEvent && name == "DTEND":
+			if end, err = parseICSDate(value, params, loc); err != nil {
+				return nil, err
+			}
+		case inEvent && name == "SUMMARY":
+			summary = unescapeText(value)
+		}
+	}
+
+	if !calendar || inEvent {
+		return nil, ErrInvalidICS
+	}
+
+	return holidays, nil
+}
+
+// unfold joins continuation lines, which start with a space or a tab.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, ErrInvalidICS
+	}
+
+	return lines, nil
+}
+
+// splitProperty splits "NAME;PARAM=x:value" into its name, parameters and
+// value.
+func splitProperty(line string) (string, map[string]string, string, bool) {
+	head, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(head, ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		if key, val, ok := strings.Cut(part, "="); ok {
+			params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+		}
+	}
+
+	return strings.ToUpper(parts[0]), params, strings.TrimSpace(value), true
+}
+
+// parseICSDate returns a DATE value as midnight in loc, and a DATE-TIME value
+// as the same moment in loc.
+func parseICSDate(value string, params map[string]string, loc *time.Location) (time.Time, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, ErrInvalidICS
+		}
+		return t, nil
+	}
+
+	var (
+		t   time.Time
+		err error
+	)
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse("20060102T150405Z", value)
+	case params["TZID"] != "":
+		tz, tzErr := time.LoadLocation(params["TZID"])
+		if tzErr != nil {
+			tz = loc
+		}
+		t, err = time.ParseInLocation("20060102T150405", value, tz)
+	default:
+		t, err = time.ParseInLocation("20060102T150405", value, loc)
+	}
+	if err != nil {
+		return time.Time{}, ErrInvalidICS
+	}
+
+	return t.In(loc), nil
+}
+
+func unescapeText(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
@@ -0,0 +1,116 @@
+package calendar
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func icsFile(events ...string) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(events, "") + "END:VCALENDAR\r\n"
+}
+
+func icsEvent(lines ...string) string {
+	return "BEGIN:VEVENT\r\n" + strings.Join(lines, "\r\n") + "\r\nEND:VEVENT\r\n"
+}
+
+func TestParseICS(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		event string
+		want  []string
+	}{
+		"all day": {
+			icsEvent("DTSTART;VALUE=DATE:20240101", "SUMMARY:Tahun Baru"),
+			[]string{"2024-01-01"},
+		},
+		"multi-day, DTEND exclusive": {
+			icsEvent("DTSTART;VALUE=DATE:20240410", "DTEND;VALUE=DATE:20240412", "SUMMARY:Idul Fitri"),
+			[]string{"2024-04-10", "2024-04-11"},
+		},
+		"timed within a day": {
+			icsEvent("DTSTART:20240101T090000", "DTEND:20240101T170000"),
+			[]string{"2024-01-01"},
+		},
+		"timed into the next day": {
+			icsEvent("DTSTART:20240101T220000", "DTEND:20240102T020000"),
+			[]string{"2024-01-01", "2024-01-02"},
+		},
+		"timed until midnight": {
+			icsEvent("DTSTART:20240101T080000", "DTEND:20240102T000000"),
+			[]string{"2024-01-01"},
+		},
+		"UTC time on the next local day": {
+			icsEvent("DTSTART:20240101T200000Z"),
+			[]string{"2024-01-02"},
+		},
+		"TZID time on the next local day": {
+			icsEvent("DTSTART;TZID=America/New_York:20240101T230000", "DTEND;TZID=America/New_York:20240101T235900"),
+			[]string{"2024-01-02"},
+		},
+		"capped length": {
+			icsEvent("DTSTART;VALUE=DATE:20240101", "DTEND;VALUE=DATE:20250101"),
+			days(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), maxEventDays),
+		},
+	} {
+		holidays, err := parseICS(strings.NewReader(icsFile(tc.event)), jakarta)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		got := make([]string, 0, len(holidays))
+		for _, h := range holidays {
+			if h.Date.Location() != jakarta || !h.Date.Equal(midnight(h.Date)) {
+				t.Errorf("%s: %s is not a local midnight", name, h.Date)
+			}
+			got = append(got, h.Date.Format(time.DateOnly))
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: got %v, want %v", name, got, tc.want)
+		}
+	}
+}
+
+func TestParseICSSummary(t *testing.T) {
+	file := icsFile(icsEvent(
+		"DTSTART;VALUE=DATE:20240311",
+		"SUMMARY:Hari Suci Nyepi\\, Tahun Baru",
+		"  Saka 1946",
+	))
+
+	holidays, err := parseICS(strings.NewReader(file), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holidays) != 1 || holidays[0].Name != "Hari Suci Nyepi, Tahun Baru Saka 1946" {
+		t.Fatalf("got %+v", holidays)
+	}
+}
+
+func TestParseICSInvalid(t *testing.T) {
+	for name, file := range map[string]string{
+		"no calendar":    icsEvent("DTSTART;VALUE=DATE:20240101"),
+		"no DTSTART":     icsFile(icsEvent("SUMMARY:Libur")),
+		"bad date":       icsFile(icsEvent("DTSTART;VALUE=DATE:2024-01-01")),
+		"unclosed event": "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20240101\r\nEND:VCALENDAR\r\n",
+		"bad date-time":  icsFile(icsEvent("DTSTART:20240101T25")),
+	} {
+		if _, err := parseICS(strings.NewReader(file), time.UTC); !errors.Is(err, ErrInvalidICS) {
+			t.Errorf("%s: got %v, want ErrInvalidICS", name, err)
+		}
+	}
+}
+
+func days(from time.Time, n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = from.AddDate(0, 0, i).Format(time.DateOnly)
+	}
+	return out
+}
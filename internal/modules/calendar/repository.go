@@ -0,0 +1,177 @@
+package calendar
+
+import (
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CalendarRepository interface {
+	GetAreaCalendar(areaID pgtype.UUID) (db.GetAreaCalendarRow, error)
+	GetDefaultCalendar() (db.GetDefaultCalendarRow, error)
+	GetCalendarByArea(areaID pgtype.UUID) (db.GetCalendarByAreaRow, error)
+	GetWorkingHours(calendarID uuid.UUID) ([]db.GetWorkingHoursRow, error)
+	GetCalendarHolidays(calendarIDs []uuid.UUID) ([]db.GetCalendarHolidaysRow, error)
+	SetWorkingHours(areaID pgtype.UUID, timezone string, hours []db.CreateWorkingHoursParams) (uuid.UUID, error)
+	DeleteAreaCalendar(areaID uuid.UUID) (uuid.UUID, error)
+	UpsertHolidays(arg []db.UpsertHolidayParams) ([]db.CalendarHoliday, error)
+	DeleteHoliday(id uuid.UUID) (uuid.UUID, error)
+}
+
+type repository struct {
+	pool *pgxpool.Pool
+	db   *db.Queries
+}
+
+func NewCalendarRepository(pool *pgxpool.Pool) CalendarRepository {
+	return &repository{pool: pool, db: db.New(pool)}
+}
+
+// withTx runs fn inside a single database transaction.
+// The transaction is committed only when fn returns nil.
+func (r *repository) withTx(fn func(q *db.Queries) error) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.db.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *repository) GetAreaCalendar(areaID pgtype.UUID) (db.GetAreaCalendarRow, error) {
+	return r.db.GetAreaCalendar(context.Background(), areaID)
+}
+
+func (r *repository) GetDefaultCalendar() (db.GetDefaultCalendarRow, error) {
+	return r.db.GetDefaultCalendar(context.Background())
+}
+
+func (r *repository) GetCalendarByArea(areaID pgtype.UUID) (db.GetCalendarByAreaRow, error) {
+	return r.db.GetCalendarByArea(context.Background(), areaID)
+}
+
+func (r *repository) GetWorkingHours(calendarID uuid.UUID) ([]db.GetWorkingHoursRow, error) {
+	return r.db.GetWorkingHours(context.Background(), calendarID)
+}
+
+func (r *repository) GetCalendarHolidays(calendarIDs []uuid.UUID) ([]db.GetCalendarHolidaysRow, error) {
+	return r.db.GetCalendarHolidays(context.Background(), calendarIDs)
+}
+
+// SetWorkingHours replaces the working hours and time zone of the calendar of
+// areaID, creating the calendar when the area has none yet. An invalid areaID
+// is the default calendar. Like every calendar change, it drops the SLA
+// deadlines computed with the old calendar.
+func (r *repository) SetWorkingHours(areaID pgtype.UUID, timezone string, hours []db.CreateWorkingHoursParams) (uuid.UUID, error) {
+	var calendarID uuid.UUID
+
+	err := r.withTx(func(q *db.Queries) error {
+		ctx := context.Background()
+
+		current, err := q.GetCalendarByArea(ctx, areaID)
+		switch {
+		case err == nil:
+			calendarID = current.ID
+			if err := q.UpdateCalendarTimezone(ctx, db.UpdateCalendarTimezoneParams{
+				Timezone: timezone,
+				ID:       calendarID,
+			}); err != nil {
+				return err
+			}
+		case err.Error() == pkg.ErrNoRows:
+			calendarID, err = q.CreateBusinessCalendar(ctx, db.CreateBusinessCalendarParams{
+				AreaID:   areaID,
+				Timezone: timezone,
+			})
+			if err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		if err := q.DeleteWorkingHours(ctx, calendarID); err != nil {
+			return err
+		}
+
+		for _, shift := range hours {
+			shift.CalendarID = calendarID
+			if err := q.CreateWorkingHours(ctx, shift); err != nil {
+				return err
+			}
+		}
+
+		return q.ClearSLADeadlines(ctx)
+	})
+
+	return calendarID, err
+}
+
+func (r *repository) DeleteAreaCalendar(areaID uuid.UUID) (uuid.UUID, error) {
+	var calendarID uuid.UUID
+
+	err := r.withTx(func(q *db.Queries) error {
+		ctx := context.Background()
+
+		var err error
+		calendarID, err = q.DeleteAreaCalendar(ctx, areaID)
+		if err != nil {
+			return err
+		}
+
+		return q.ClearSLADeadlines(ctx)
+	})
+
+	return calendarID, err
+}
+
+// UpsertHolidays writes a batch of holidays in one transaction, renaming the
+// ones that already exist.
+func (r *repository) UpsertHolidays(arg []db.UpsertHolidayParams) ([]db.CalendarHoliday, error) {
+	result := make([]db.CalendarHoliday, 0, len(arg))
+
+	err := r.withTx(func(q *db.Queries) error {
+		for _, holiday := range arg {
+			row, err := q.UpsertHoliday(context.Background(), holiday)
+			if err != nil {
+				return err
+			}
+			result = append(result, row)
+		}
+		return q.ClearSLADeadlines(context.Background())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *repository) DeleteHoliday(id uuid.UUID) (uuid.UUID, error) {
+	var calendarID uuid.UUID
+
+	err := r.withTx(func(q *db.Queries) error {
+		ctx := context.Background()
+
+		var err error
+		calendarID, err = q.DeleteHoliday(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return q.ClearSLADeadlines(ctx)
+	})
+
+	return calendarID, err
+}
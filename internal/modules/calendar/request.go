@@ -0,0 +1,21 @@
+package calendar
+
+type WorkingHoursRequest struct {
+	// empty sets the default calendar
+	AreaID   string         `json:"area_id" validate:"omitempty,uuid"`
+	Timezone string         `json:"timezone" validate:"required"`
+	Hours    []ShiftRequest `json:"hours" validate:"dive"`
+}
+
+type ShiftRequest struct {
+	Weekday int    `json:"weekday" validate:"min=0,max=6"` // 0 is Sunday
+	Start   string `json:"start" validate:"required"`      // HH:MM
+	End     string `json:"end" validate:"required"`        // HH:MM
+}
+
+type HolidayRequest struct {
+	// empty adds a holiday to the default calendar, observed in every area
+	AreaID string `json:"area_id" validate:"omitempty,uuid"`
+	Date   string `json:"date" validate:"required"` // YYYY-MM-DD
+	Name   string `json:"name" validate:"required,max=255"`
+}
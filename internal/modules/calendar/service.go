@@ -0,0 +1,459 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/internal/modules/auditlogs"
+	"hubku/lapor_warga_be_v2/pkg"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrInvalidTimezone = errors.New("invalid timezone")
+	ErrInvalidHours    = errors.New("invalid working hours")
+	ErrInvalidDate     = errors.New("invalid date")
+	ErrNoCalendar      = errors.New("area has no calendar")
+	ErrHolidayNotFound = errors.New("holiday not found")
+)
+
+// ShiftResponse is a shift as HH:MM times.
+type ShiftResponse struct {
+	Weekday int16  `json:"weekday"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// CalendarResponse is the calendar an area follows: its own, the nearest
+// one up the area hierarchy, or the default one.
+type CalendarResponse struct {
+	ID       uuid.UUID                   `json:"id"`
+	AreaID   pgtype.UUID                 `json:"area_id"`
+	Timezone string                      `json:"timezone"`
+	Hours    []ShiftResponse             `json:"hours"`
+	Holidays []db.GetCalendarHolidaysRow `json:"holidays"`
+}
+
+type CalendarService interface {
+	ForArea(areaID pgtype.UUID) (Calendar, error)
+	Deadline(areaID pgtype.UUID, start time.Time, d time.Duration) (Deadline, error)
+	GetCalendar(areaID pgtype.UUID) (CalendarResponse, error)
+	SetWorkingHours(currentUserID uuid.UUID, req WorkingHoursRequest) (CalendarResponse, error)
+	DeleteAreaCalendar(currentUserID uuid.UUID, areaID uuid.UUID) error
+	AddHoliday(currentUserID uuid.UUID, req HolidayRequest) (db.CalendarHoliday, error)
+	DeleteHoliday(currentUserID uuid.UUID, id uuid.UUID) error
+	ImportHolidays(currentUserID uuid.UUID, areaID string, r io.Reader) ([]db.CalendarHoliday, error)
+}
+
+type service struct {
+	repo       CalendarRepository
+	logService auditlogs.LogsService
+}
+
+func NewCalendarService(repo CalendarRepository, logService auditlogs.LogsService) CalendarService {
+	return &service{repo: repo, logService: logService}
+}
+
+// ForArea returns the business calendar of an area: its own calendar or the
+// one of its nearest ancestor that has one, otherwise the default calendar.
+// Holidays of the default calendar apply in every area.
+func (s *service) ForArea(areaID pgtype.UUID) (Calendar, error) {
+	_, result, err := s.load(areaID)
+	return result, err
+}
+
+// Deadline returns when d of business time counted from start runs out in
+// an area, and how much of it is left now.
+func (s *service) Deadline(areaID pgtype.UUID, start time.Time, d time.Duration) (Deadline, error) {
+	cal, err := s.ForArea(areaID)
+	if err != nil {
+		return Deadline{}, err
+	}
+	return cal.Deadline(start, d, time.Now())
+}
+
+// GetCalendar returns the calendar an area follows, with its holidays from
+// today on.
+func (s *service) GetCalendar(areaID pgtype.UUID) (CalendarResponse, error) {
+	response, _, err := s.load(areaID)
+	if err != nil {
+		return CalendarResponse{}, err
+	}
+
+	today := time.Now().Format(time.DateOnly)
+	upcoming := []db.GetCalendarHolidaysRow{}
+	for _, holiday := range response.Holidays {
+		if holiday.HolidayDate.Time.Format(time.DateOnly) >= today {
+			upcoming = append(upcoming, holiday)
+		}
+	}
+	response.Holidays = upcoming
+
+	return response, nil
+}
+
+// SetWorkingHours replaces the working hours and time zone of an area's own
+// calendar, or of the default calendar when no area is given.
+func (s *service) SetWorkingHours(currentUserID uuid.UUID, req WorkingHoursRequest) (CalendarResponse, error) {
+	areaID, err := parseAreaID(req.AreaID)
+	if err != nil {
+		return CalendarResponse{}, err
+	}
+
+	if _, err := time.LoadLocation(req.Timezone); err != nil || req.Timezone == "Local" {
+		return CalendarResponse{}, ErrInvalidTimezone
+	}
+
+	hours, err := parseShifts(req.Hours)
+	if err != nil {
+		return CalendarResponse{}, err
+	}
+
+	calendarID, err := s.repo.SetWorkingHours(areaID, req.Timezone, hours)
+	if err != nil {
+		return CalendarResponse{}, err
+	}
+
+	// log working hours
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"area_id":  areaID,
+			"timezone": req.Timezone,
+			"hours":    req.Hours,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityCalendars),
+			Action:      string(pkg.LogTypeUpdate),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    calendarID,
+			PerformedBy: currentUserID,
+		})
+	}()
+
+	return s.GetCalendar(areaID)
+}
+
+// DeleteAreaCalendar removes the calendar of an area, which then follows the
+// calendar of its parent areas again.
+func (s *service) DeleteAreaCalendar(currentUserID uuid.UUID, areaID uuid.UUID) error {
+	calendarID, err := s.repo.DeleteAreaCalendar(areaID)
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return ErrNoCalendar
+		}
+		return err
+	}
+
+	// log delete calendar
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"area_id": areaID,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityCalendars),
+			Action:      string(pkg.LogTypeDelete),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    calendarID,
+			PerformedBy: currentUserID,
+		})
+	}()
+
+	return nil
+}
+
+// AddHoliday adds a holiday to an area's own calendar, or to the default
+// calendar when no area is given. A holiday on a date that already has one
+// renames it.
+func (s *service) AddHoliday(currentUserID uuid.UUID, req HolidayRequest) (db.CalendarHoliday, error) {
+	date, err := time.Parse(time.DateOnly, req.Date)
+	if err != nil {
+		return db.CalendarHoliday{}, ErrInvalidDate
+	}
+
+	current, err := s.calendarOf(req.AreaID)
+	if err != nil {
+		return db.CalendarHoliday{}, err
+	}
+
+	result, err := s.upsertHolidays(currentUserID, current, []holiday{{Date: date, Name: req.Name}})
+	if err != nil {
+		return db.CalendarHoliday{}, err
+	}
+
+	return result[0], nil
+}
+
+func (s *service) DeleteHoliday(currentUserID uuid.UUID, id uuid.UUID) error {
+	calendarID, err := s.repo.DeleteHoliday(id)
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return ErrHolidayNotFound
+		}
+		return err
+	}
+
+	// log delete holiday
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"holiday_id": id,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityCalendars),
+			Action:      string(pkg.LogTypeDelete),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    calendarID,
+			PerformedBy: currentUserID,
+		})
+	}()
+
+	return nil
+}
+
+// ImportHolidays adds every day covered by an event of an iCalendar file as
+// a holiday of an area's own calendar, or of the default calendar when no
+// area is given. Importing the same file twice changes nothing.
+func (s *service) ImportHolidays(currentUserID uuid.UUID, areaID string, r io.Reader) ([]db.CalendarHoliday, error) {
+	current, err := s.calendarOf(areaID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(current.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := parseICS(r, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(holidays) == 0 {
+		return []db.CalendarHoliday{}, nil
+	}
+
+	return s.upsertHolidays(currentUserID, current, holidays)
+}
+
+// calendarOf returns the calendar set on an area itself, the default
+// calendar when areaID is empty.
+func (s *service) calendarOf(areaID string) (db.GetCalendarByAreaRow, error) {
+	id, err := parseAreaID(areaID)
+	if err != nil {
+		return db.GetCalendarByAreaRow{}, err
+	}
+
+	current, err := s.repo.GetCalendarByArea(id)
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return db.GetCalendarByAreaRow{}, ErrNoCalendar
+		}
+		return db.GetCalendarByAreaRow{}, err
+	}
+
+	return current, nil
+}
+
+func (s *service) upsertHolidays(currentUserID uuid.UUID, current db.GetCalendarByAreaRow, holidays []holiday) ([]db.CalendarHoliday, error) {
+	arg := make([]db.UpsertHolidayParams, 0, len(holidays))
+	for _, h := range holidays {
+		name := h.Name
+		if name == "" {
+			name = "Holiday"
+		}
+		if len(name) > 255 {
+			name = name[:255]
+		}
+
+		arg = append(arg, db.UpsertHolidayParams{
+			CalendarID: current.ID,
+			HolidayDate: pgtype.Date{
+				Time:  time.Date(h.Date.Year(), h.Date.Month(), h.Date.Day(), 0, 0, 0, 0, time.UTC),
+				Valid: true,
+			},
+			Name: name,
+		})
+	}
+
+	result, err := s.repo.UpsertHolidays(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	// log holidays
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"area_id":  current.AreaID,
+			"holidays": len(result),
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityCalendars),
+			Action:      string(pkg.LogTypeCreate),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    current.ID,
+			PerformedBy: currentUserID,
+		})
+	}()
+
+	return result, nil
+}
+
+// load reads the calendar an area follows, both as a response and as a
+// Calendar to compute with.
+func (s *service) load(areaID pgtype.UUID) (CalendarResponse, Calendar, error) {
+	current, err := s.repo.GetAreaCalendar(areaID)
+	if err != nil {
+		if err.Error() != pkg.ErrNoRows {
+			return CalendarResponse{}, Calendar{}, err
+		}
+
+		// the area does not exist, follow the default calendar
+		fallback, err := s.repo.GetDefaultCalendar()
+		if err != nil {
+			return CalendarResponse{}, Calendar{}, err
+		}
+		current = db.GetAreaCalendarRow(fallback)
+	}
+
+	loc, err := time.LoadLocation(current.Timezone)
+	if err != nil {
+		return CalendarResponse{}, Calendar{}, err
+	}
+
+	hours, err := s.repo.GetWorkingHours(current.ID)
+	if err != nil {
+		return CalendarResponse{}, Calendar{}, err
+	}
+
+	calendarIDs := []uuid.UUID{current.ID}
+	if current.AreaID.Valid {
+		fallback, err := s.repo.GetDefaultCalendar()
+		if err != nil {
+			return CalendarResponse{}, Calendar{}, err
+		}
+		calendarIDs = append(calendarIDs, fallback.ID)
+	}
+
+	holidays, err := s.repo.GetCalendarHolidays(calendarIDs)
+	if err != nil {
+		return CalendarResponse{}, Calendar{}, err
+	}
+
+	response := CalendarResponse{
+		ID:       current.ID,
+		AreaID:   current.AreaID,
+		Timezone: current.Timezone,
+		Hours:    make([]ShiftResponse, 0, len(hours)),
+		Holidays: holidays,
+	}
+	result := Calendar{
+		Location: loc,
+		Holidays: make(map[string]string, len(holidays)),
+	}
+
+	for _, row := range hours {
+		shift := Shift{
+			Start: time.Duration(row.StartTime.Microseconds) * time.Microsecond,
+			End:   time.Duration(row.EndTime.Microseconds) * time.Microsecond,
+		}
+		result.Hours[row.Weekday] = append(result.Hours[row.Weekday], shift)
+		response.Hours = append(response.Hours, ShiftResponse{
+			Weekday: row.Weekday,
+			Start:   formatClock(shift.Start),
+			End:     formatClock(shift.End),
+		})
+	}
+
+	for _, holiday := range holidays {
+		result.Holidays[holiday.HolidayDate.Time.Format(time.DateOnly)] = holiday.Name
+	}
+
+	return response, result, nil
+}
+
+func parseAreaID(areaID string) (pgtype.UUID, error) {
+	if areaID == "" {
+		return pgtype.UUID{}, nil
+	}
+
+	id, err := uuid.Parse(areaID)
+	if err != nil {
+		return pgtype.UUID{}, ErrNoCalendar
+	}
+
+	return pgtype.UUID{Bytes: id, Valid: true}, nil
+}
+
+// parseShifts checks that every shift ends after it starts and that the
+// shifts of a day do not overlap.
+func parseShifts(req []ShiftRequest) ([]db.CreateWorkingHoursParams, error) {
+	var days [7][]Shift
+
+	for _, shift := range req {
+		start, err := parseClock(shift.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(shift.End)
+		if err != nil {
+			return nil, err
+		}
+		if end <= start || shift.Weekday < 0 || shift.Weekday > 6 {
+			return nil, ErrInvalidHours
+		}
+		days[shift.Weekday] = append(days[shift.Weekday], Shift{Start: start, End: end})
+	}
+
+	hours := make([]db.CreateWorkingHoursParams, 0, len(req))
+	for weekday, shifts := range days {
+		sort.Slice(shifts, func(i, j int) bool { return shifts[i].Start < shifts[j].Start })
+
+		for i, shift := range shifts {
+			if i > 0 && shift.Start < shifts[i-1].End {
+				return nil, ErrInvalidHours
+			}
+
+			hours = append(hours, db.CreateWorkingHoursParams{
+				Weekday: int16(weekday),
+				StartTime: pgtype.Time{
+					Microseconds: shift.Start.Microseconds(),
+					Valid:        true,
+				},
+				EndTime: pgtype.Time{
+					Microseconds: shift.End.Microseconds(),
+					Valid:        true,
+				},
+			})
+		}
+	}
+
+	return hours, nil
+}
+
+// parseClock reads an HH:MM time of day, 24:00 being the end of the day.
+func parseClock(value string) (time.Duration, error) {
+	if value == "24:00" {
+		return 24 * time.Hour, nil
+	}
+
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, ErrInvalidHours
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
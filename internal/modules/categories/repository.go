@@ -17,6 +17,7 @@ type CategoriesRepository interface {
 	SearchCategories(searchTerm, orderBy, sortOrder string) ([]db.SearchCategoriesRow, error)
 	ToggleCategoryActiveStatus(id uuid.UUID) (db.ToggleCategoryActiveStatusRow, error)
 	UpdateCategory(arg db.UpdateCategoryParams) (uuid.UUID, error)
+	UpdateCategorySLA(arg db.UpdateCategorySLAParams) (db.UpdateCategorySLARow, error)
 	DeleteCategory(id uuid.UUID) (uuid.UUID, error)
 }
 
@@ -86,6 +87,10 @@ func (r *repository) UpdateCategory(arg db.UpdateCategoryParams) (uuid.UUID, err
 	return r.db.UpdateCategory(context.Background(), arg)
 }
 
+func (r *repository) UpdateCategorySLA(arg db.UpdateCategorySLAParams) (db.UpdateCategorySLARow, error) {
+	return r.db.UpdateCategorySLA(context.Background(), arg)
+}
+
 func (r *repository) DeleteCategory(id uuid.UUID) (uuid.UUID, error) {
 	return r.db.DeleteCategory(context.Background(), id)
 }
@@ -18,6 +18,13 @@ type UpdateCategoryRequest struct {
 	SortOrder int    `json:"sort_order" form:"sort_order"`
 }
 
+// UpdateCategorySLARequest sets the deadlines of reports in a category, in
+// minutes after submission. An empty value removes the deadline.
+type UpdateCategorySLARequest struct {
+	ResponseMinutes   *int `json:"response_minutes" validate:"omitempty,min=1"`
+	ResolutionMinutes *int `json:"resolution_minutes" validate:"omitempty,min=1"`
+}
+
 type SearchCategoryRequest struct {
 	SearchTerm string `json:"search_term"`
 	SortBy     string `json:"sort_by"`
@@ -1,6 +1,7 @@
 package categories
 
 import (
+	"encoding/json"
 	"fmt"
 	db "hubku/lapor_warga_be_v2/internal/database/generated"
 	"hubku/lapor_warga_be_v2/internal/modules/auditlogs"
@@ -20,6 +21,7 @@ type CategoriesService interface {
 	SearchCategories(req SearchCategoryRequest) ([]db.SearchCategoriesRow, error)
 	ToggleCategoryActiveStatus(currentUserID uuid.UUID, id uuid.UUID) (db.ToggleCategoryActiveStatusRow, error)
 	UpdateCategory(currentUserID uuid.UUID, id uuid.UUID, req UpdateCategoryRequest) (uuid.UUID, error)
+	UpdateCategorySLA(currentUserID uuid.UUID, id uuid.UUID, req UpdateCategorySLARequest) (db.UpdateCategorySLARow, error)
 	DeleteCategory(currentUserID uuid.UUID, id uuid.UUID) (uuid.UUID, error)
 }
 
@@ -150,6 +152,39 @@ func (s *service) UpdateCategory(currentUserID, id uuid.UUID, req UpdateCategory
 	return result, nil
 }
 
+func (s *service) UpdateCategorySLA(currentUserID, id uuid.UUID, req UpdateCategorySLARequest) (db.UpdateCategorySLARow, error) {
+	arg := db.UpdateCategorySLAParams{ID: id}
+	if req.ResponseMinutes != nil {
+		arg.ResponseSlaMinutes = pgtype.Int4{Int32: int32(*req.ResponseMinutes), Valid: true}
+	}
+	if req.ResolutionMinutes != nil {
+		arg.ResolutionSlaMinutes = pgtype.Int4{Int32: int32(*req.ResolutionMinutes), Valid: true}
+	}
+
+	result, err := s.repo.UpdateCategorySLA(arg)
+	if err != nil {
+		return db.UpdateCategorySLARow{}, err
+	}
+
+	// log update category sla
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"response_sla_minutes":   result.ResponseSlaMinutes,
+			"resolution_sla_minutes": result.ResolutionSlaMinutes,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityCategories),
+			Action:      string(pkg.LogTypeUpdate),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    id,
+			PerformedBy: currentUserID,
+		})
+	}()
+
+	return result, nil
+}
+
 func (s *service) DeleteCategory(currentUserID, id uuid.UUID) (uuid.UUID, error) {
 	result, err := s.repo.DeleteCategory(id)
 	if err != nil {
@@ -0,0 +1,55 @@
+package comments
+
+import (
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CommentsRepository interface {
+	CreateComment(arg db.CreateReportCommentParams) (db.ReportComment, error)
+	GetCommentByID(id uuid.UUID) (db.ReportComment, error)
+	GetCommentDepth(id uuid.UUID) (int32, error)
+	UpdateComment(arg db.UpdateReportCommentParams) (db.ReportComment, error)
+	SoftDeleteComment(id uuid.UUID) error
+	GetTopLevelComments(arg db.GetTopLevelReportCommentsParams) ([]db.GetTopLevelReportCommentsRow, error)
+	GetReplies(arg db.GetReportCommentRepliesParams) ([]db.GetReportCommentRepliesRow, error)
+}
+
+type repository struct {
+	db *db.Queries
+}
+
+func NewCommentsRepository(pool *pgxpool.Pool) CommentsRepository {
+	return &repository{db: db.New(pool)}
+}
+
+func (r *repository) CreateComment(arg db.CreateReportCommentParams) (db.ReportComment, error) {
+	return r.db.CreateReportComment(context.Background(), arg)
+}
+
+func (r *repository) GetCommentByID(id uuid.UUID) (db.ReportComment, error) {
+	return r.db.GetReportCommentByID(context.Background(), id)
+}
+
+func (r *repository) GetCommentDepth(id uuid.UUID) (int32, error) {
+	return r.db.GetReportCommentDepth(context.Background(), id)
+}
+
+func (r *repository) UpdateComment(arg db.UpdateReportCommentParams) (db.ReportComment, error) {
+	return r.db.UpdateReportComment(context.Background(), arg)
+}
+
+func (r *repository) SoftDeleteComment(id uuid.UUID) error {
+	return r.db.SoftDeleteReportComment(context.Background(), id)
+}
+
+func (r *repository) GetTopLevelComments(arg db.GetTopLevelReportCommentsParams) ([]db.GetTopLevelReportCommentsRow, error) {
+	return r.db.GetTopLevelReportComments(context.Background(), arg)
+}
+
+func (r *repository) GetReplies(arg db.GetReportCommentRepliesParams) ([]db.GetReportCommentRepliesRow, error) {
+	return r.db.GetReportCommentReplies(context.Background(), arg)
+}
@@ -0,0 +1,12 @@
+package comments
+
+import "github.com/google/uuid"
+
+type CreateCommentRequest struct {
+	Content  string     `json:"content" form:"content" validate:"required,min=1,max=2000"`
+	ParentID *uuid.UUID `json:"parent_id" form:"parent_id"`
+}
+
+type UpdateCommentRequest struct {
+	Content string `json:"content" form:"content" validate:"required,min=1,max=2000"`
+}
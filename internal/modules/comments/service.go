@@ -0,0 +1,401 @@
+package comments
+
+import (
+	"encoding/json"
+	"errors"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/internal/modules/auditlogs"
+	"hubku/lapor_warga_be_v2/internal/modules/notifications"
+	"hubku/lapor_warga_be_v2/internal/modules/realtime"
+	"hubku/lapor_warga_be_v2/internal/modules/reports"
+	"hubku/lapor_warga_be_v2/pkg"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/spf13/viper"
+)
+
+var (
+	ErrCommentNotFound = errors.New("comment not found")
+	ErrParentNotFound  = errors.New("parent comment not found")
+	ErrMaxDepth        = errors.New("comment thread is too deep")
+	ErrForbidden       = errors.New("not allowed to modify this comment")
+)
+
+// DeletedPlaceholder replaces the content of deleted comments that still have replies.
+const DeletedPlaceholder = "[deleted]"
+
+// Comment is a node of a comment thread.
+type Comment struct {
+	ID        uuid.UUID          `json:"id"`
+	ReportID  uuid.UUID          `json:"report_id"`
+	ParentID  pgtype.UUID        `json:"parent_id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Username  pgtype.Text        `json:"username"`
+	Content   string             `json:"content"`
+	IsDeleted bool               `json:"is_deleted"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	Replies   []*Comment         `json:"replies"`
+}
+
+type CommentPage struct {
+	Comments   []*Comment `json:"comments"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// commentCursor is the position of the last top-level comment of a page.
+type commentCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+type CommentsService interface {
+	CreateComment(actor reports.Actor, reportID uuid.UUID, req CreateCommentRequest) (db.ReportComment, error)
+	UpdateComment(actor reports.Actor, id uuid.UUID, req UpdateCommentRequest) (db.ReportComment, error)
+	DeleteComment(actor reports.Actor, id uuid.UUID) error
+	GetComments(currentUserID uuid.UUID, role string, reportID uuid.UUID, cursor string, limit int) (CommentPage, error)
+}
+
+type service struct {
+	repo          CommentsRepository
+	reportService reports.ReportsService
+	notifications notifications.NotificationsService
+	realtime      realtime.RealtimeService
+	logService    auditlogs.LogsService
+	maxDepth      int32
+}
+
+func NewCommentsService(repo CommentsRepository, reportService reports.ReportsService, notificationService notifications.NotificationsService, realtimeService realtime.RealtimeService, logService auditlogs.LogsService) CommentsService {
+	viper.SetDefault("COMMENT_MAX_DEPTH", 5)
+
+	return &service{
+		repo:          repo,
+		reportService: reportService,
+		notifications: notificationService,
+		realtime:      realtimeService,
+		logService:    logService,
+		maxDepth:      viper.GetInt32("COMMENT_MAX_DEPTH"),
+	}
+}
+
+func (s *service) CreateComment(actor reports.Actor, reportID uuid.UUID, req CreateCommentRequest) (db.ReportComment, error) {
+	// only reports visible to the actor can be commented on
+	report, err := s.reportService.GetReportByID(actor.ID, string(actor.Role), reportID)
+	if err != nil {
+		return db.ReportComment{}, err
+	}
+
+	var (
+		parentID       pgtype.UUID
+		parentAuthorID pgtype.UUID
+	)
+
+	if req.ParentID != nil {
+		parent, err := s.repo.GetCommentByID(*req.ParentID)
+		if err != nil {
+			if err.Error() == pkg.ErrNoRows {
+				return db.ReportComment{}, ErrParentNotFound
+			}
+			return db.ReportComment{}, err
+		}
+
+		if parent.ReportID != reportID || parent.DeletedAt.Valid {
+			return db.ReportComment{}, ErrParentNotFound
+		}
+
+		depth, err := s.repo.GetCommentDepth(parent.ID)
+		if err != nil {
+			return db.ReportComment{}, err
+		}
+		if depth >= s.maxDepth {
+			return db.ReportComment{}, ErrMaxDepth
+		}
+
+		parentID = pgtype.UUID{Bytes: parent.ID, Valid: true}
+		parentAuthorID = parent.UserID
+	}
+
+	comment, err := s.repo.CreateComment(db.CreateReportCommentParams{
+		ReportID: reportID,
+		ParentID: parentID,
+		UserID: pgtype.UUID{
+			Bytes: actor.ID,
+			Valid: true,
+		},
+		Content: req.Content,
+	})
+	if err != nil {
+		return db.ReportComment{}, err
+	}
+
+	// log create comment
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"report_id": reportID,
+			"parent_id": parentID,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityComments),
+			Action:      string(pkg.LogTypeCreate),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    comment.ID,
+			PerformedBy: actor.ID,
+		})
+	}()
+
+	s.realtime.Publish(realtime.Event{
+		Type:     pkg.EventCommentCreated,
+		ReportID: reportID,
+		AreaID:   report.AreaID,
+		Data: map[string]interface{}{
+			"comment_id": comment.ID,
+			"parent_id":  parentID,
+			"user_id":    actor.ID,
+		},
+	})
+
+	// tell the author of the comment replied to
+	if parentAuthorID.Valid {
+		go func() {
+			if _, err := s.notifications.Notify(notifications.Event{
+				Type:       pkg.NotificationCommentReply,
+				Recipients: []uuid.UUID{parentAuthorID.Bytes},
+				ReportID:   reportID,
+				ActorID:    actor.ID,
+				Data: map[string]interface{}{
+					"comment_id": comment.ID,
+					"parent_id":  parentID,
+				},
+			}); err != nil {
+				log.Printf("failed to notify reply to comment %s: %v", uuid.UUID(parentID.Bytes), err)
+			}
+		}()
+	}
+
+	return comment, nil
+}
+
+func (s *service) UpdateComment(actor reports.Actor, id uuid.UUID, req UpdateCommentRequest) (db.ReportComment, error) {
+	comment, err := s.getVisibleComment(actor, id)
+	if err != nil {
+		return db.ReportComment{}, err
+	}
+
+	// only the author can edit a comment
+	if !comment.UserID.Valid || comment.UserID.Bytes != actor.ID {
+		return db.ReportComment{}, ErrForbidden
+	}
+
+	updated, err := s.repo.UpdateComment(db.UpdateReportCommentParams{
+		Content: req.Content,
+		ID:      id,
+	})
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return db.ReportComment{}, ErrCommentNotFound
+		}
+		return db.ReportComment{}, err
+	}
+
+	return updated, nil
+}
+
+func (s *service) DeleteComment(actor reports.Actor, id uuid.UUID) error {
+	// officials only moderate comments on reports in their jurisdiction
+	comment, err := s.getVisibleComment(actor, id)
+	if err != nil {
+		return err
+	}
+
+	// authors can delete their own comments, moderators any comment
+	isAuthor := comment.UserID.Valid && comment.UserID.Bytes == actor.ID
+	if !isAuthor && actor.Role != pkg.RoleAdmin && actor.Role != pkg.RoleOfficial {
+		return ErrForbidden
+	}
+
+	if err := s.repo.SoftDeleteComment(id); err != nil {
+		return err
+	}
+
+	// log delete comment
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"report_id": comment.ReportID,
+			"content":   comment.Content,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityComments),
+			Action:      string(pkg.LogTypeDelete),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    id,
+			PerformedBy: actor.ID,
+		})
+	}()
+
+	return nil
+}
+
+// GetComments returns a page of top-level comments, newest first, each with
+// its replies nested below it (oldest first) up to the depth limit.
+func (s *service) GetComments(currentUserID uuid.UUID, role string, reportID uuid.UUID, cursor string, limit int) (CommentPage, error) {
+	if _, err := s.reportService.GetReportByID(currentUserID, role, reportID); err != nil {
+		return CommentPage{}, err
+	}
+
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	arg := db.GetTopLevelReportCommentsParams{
+		ReportID: reportID,
+		// fetch one extra row to know whether there is a next page
+		LimitCount: int32(limit + 1),
+	}
+
+	if cursor != "" {
+		var position commentCursor
+		if err := pkg.DecodeCursor(cursor, &position); err != nil {
+			return CommentPage{}, err
+		}
+		arg.CursorCreatedAt = pgtype.Timestamptz{Time: position.CreatedAt, Valid: true}
+		arg.CursorID = pgtype.UUID{Bytes: position.ID, Valid: true}
+	}
+
+	rows, err := s.repo.GetTopLevelComments(arg)
+	if err != nil {
+		return CommentPage{}, err
+	}
+
+	page := CommentPage{Comments: []*Comment{}}
+
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		page.NextCursor = pkg.EncodeCursor(commentCursor{
+			CreatedAt: last.CreatedAt.Time,
+			ID:        last.ID,
+		})
+	}
+
+	if len(rows) == 0 {
+		return page, nil
+	}
+
+	nodes := make(map[uuid.UUID]*Comment, len(rows))
+	rootIDs := make([]uuid.UUID, 0, len(rows))
+
+	for _, row := range rows {
+		node := newComment(row.ID, row.ReportID, row.ParentID, row.UserID, row.Username, row.Content, row.CreatedAt, row.UpdatedAt, row.DeletedAt)
+		nodes[row.ID] = node
+		rootIDs = append(rootIDs, row.ID)
+		page.Comments = append(page.Comments, node)
+	}
+
+	replies, err := s.repo.GetReplies(db.GetReportCommentRepliesParams{
+		RootIds:  rootIDs,
+		MaxDepth: s.maxDepth - 1,
+	})
+	if err != nil {
+		return CommentPage{}, err
+	}
+
+	// replies come oldest first, so parents are always seen before their children
+	for _, row := range replies {
+		parent, ok := nodes[row.ParentID.Bytes]
+		if !ok {
+			continue
+		}
+
+		node := newComment(row.ID, row.ReportID, row.ParentID, row.UserID, row.Username, row.Content, row.CreatedAt, row.UpdatedAt, row.DeletedAt)
+		nodes[row.ID] = node
+		parent.Replies = append(parent.Replies, node)
+	}
+
+	for _, root := range page.Comments {
+		root.Replies = pruneDeleted(root.Replies)
+	}
+
+	return page, nil
+}
+
+func (s *service) getComment(id uuid.UUID) (db.ReportComment, error) {
+	comment, err := s.repo.GetCommentByID(id)
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return db.ReportComment{}, ErrCommentNotFound
+		}
+		return db.ReportComment{}, err
+	}
+
+	if comment.DeletedAt.Valid {
+		return db.ReportComment{}, ErrCommentNotFound
+	}
+
+	return comment, nil
+}
+
+// getVisibleComment gets a comment on a report visible to the actor. Comments
+// on other reports are reported as not found.
+func (s *service) getVisibleComment(actor reports.Actor, id uuid.UUID) (db.ReportComment, error) {
+	comment, err := s.getComment(id)
+	if err != nil {
+		return db.ReportComment{}, err
+	}
+
+	if _, err := s.reportService.GetReportByID(actor.ID, string(actor.Role), comment.ReportID); err != nil {
+		if errors.Is(err, reports.ErrReportNotFound) {
+			return db.ReportComment{}, ErrCommentNotFound
+		}
+		return db.ReportComment{}, err
+	}
+
+	return comment, nil
+}
+
+func newComment(
+	id, reportID uuid.UUID,
+	parentID, userID pgtype.UUID,
+	username pgtype.Text,
+	content string,
+	createdAt, updatedAt, deletedAt pgtype.Timestamptz,
+) *Comment {
+	c := &Comment{
+		ID:        id,
+		ReportID:  reportID,
+		ParentID:  parentID,
+		UserID:    userID,
+		Username:  username,
+		Content:   content,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+		Replies:   []*Comment{},
+	}
+
+	// keep the comment in the tree for its replies, but hide what it said and who said it
+	if deletedAt.Valid {
+		c.IsDeleted = true
+		c.Content = DeletedPlaceholder
+		c.UserID = pgtype.UUID{}
+		c.Username = pgtype.Text{}
+	}
+
+	return c
+}
+
+// pruneDeleted drops deleted comments that no longer have any replies.
+func pruneDeleted(list []*Comment) []*Comment {
+	kept := list[:0]
+	for _, c := range list {
+		c.Replies = pruneDeleted(c.Replies)
+		if c.IsDeleted && len(c.Replies) == 0 {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
@@ -0,0 +1,92 @@
+package credibility
+
+import (
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CredibilityRepository interface {
+	Apply(userID uuid.UUID, reportID uuid.UUID, overlapping []string, score func(current db.GetUserCredibilityForUpdateRow, taken int) db.CreateCredibilityEntryParams) (db.CredibilityLedger, error)
+	GetLedger(arg db.GetCredibilityLedgerParams) ([]db.GetCredibilityLedgerRow, error)
+}
+
+type repository struct {
+	pool *pgxpool.Pool
+	db   *db.Queries
+}
+
+func NewCredibilityRepository(pool *pgxpool.Pool) CredibilityRepository {
+	return &repository{pool: pool, db: db.New(pool)}
+}
+
+// withTx runs fn inside a single database transaction.
+// The transaction is committed only when fn returns nil.
+func (r *repository) withTx(fn func(q *db.Queries) error) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.db.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Apply writes the ledger entry score builds from the locked user row, then
+// moves the user to the score and status of the entry. score is also given
+// what the overlapping events already took from the user for reportID.
+// Nothing changes when the ledger already holds the event.
+func (r *repository) Apply(userID uuid.UUID, reportID uuid.UUID, overlapping []string, score func(current db.GetUserCredibilityForUpdateRow, taken int) db.CreateCredibilityEntryParams) (db.CredibilityLedger, error) {
+	var entry db.CredibilityLedger
+
+	err := r.withTx(func(q *db.Queries) error {
+		ctx := context.Background()
+
+		current, err := q.GetUserCredibilityForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		var taken int32
+		if reportID != uuid.Nil && len(overlapping) > 0 {
+			taken, err = q.GetReportPenalty(ctx, db.GetReportPenaltyParams{
+				UserID:   userID,
+				ReportID: pgtype.UUID{Bytes: reportID, Valid: true},
+				Events:   overlapping,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		entry, err = q.CreateCredibilityEntry(ctx, score(current, int(taken)))
+		if err != nil {
+			if err.Error() == pkg.ErrNoRows {
+				return ErrAlreadyScored
+			}
+			return err
+		}
+
+		return q.UpdateUserCredibility(ctx, db.UpdateUserCredibilityParams{
+			CredibilityScore: pgtype.Int2{Int16: entry.ScoreAfter, Valid: true},
+			Status:           pgtype.Text{String: entry.StatusAfter, Valid: true},
+			ID:               userID,
+		})
+	})
+
+	return entry, err
+}
+
+func (r *repository) GetLedger(arg db.GetCredibilityLedgerParams) ([]db.GetCredibilityLedgerRow, error) {
+	return r.db.GetCredibilityLedger(context.Background(), arg)
+}
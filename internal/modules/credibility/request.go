@@ -0,0 +1,6 @@
+package credibility
+
+type AdjustScoreRequest struct {
+	Delta  int    `json:"delta" validate:"required,min=-100,max=100"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
@@ -0,0 +1,236 @@
+package credibility
+
+import (
+	"encoding/json"
+	"errors"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/internal/modules/auditlogs"
+	"hubku/lapor_warga_be_v2/pkg"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/spf13/viper"
+)
+
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrAlreadyScored = errors.New("event already scored")
+)
+
+// overlaps lists the events that penalize a report for the same thing. A
+// report costs its user only the largest penalty among them, whichever order
+// they are scored in.
+var overlaps = map[pkg.CredibilityEvent][]string{
+	pkg.CredibilitySpamConfirmed: {string(pkg.CredibilityReportHidden)},
+	pkg.CredibilityReportHidden:  {string(pkg.CredibilitySpamConfirmed)},
+}
+
+const (
+	// defaultScore and defaultStatus match the column defaults of users.
+	defaultScore  = 50
+	defaultStatus = pkg.UserProbation
+
+	minScore = 0
+	maxScore = 100
+)
+
+// Event is something a user did, or that happened to their report, which
+// moves their credibility score.
+type Event struct {
+	Type   pkg.CredibilityEvent
+	UserID uuid.UUID
+	// ReportID is the report the event is about, uuid.Nil for none.
+	ReportID uuid.UUID
+	// ActorID is the user who caused the event, uuid.Nil when the system
+	// did. For upvotes it is the voter, who is scored once per report.
+	ActorID uuid.UUID
+}
+
+type CredibilityService interface {
+	Record(event Event) error
+	Adjust(actorID uuid.UUID, userID uuid.UUID, req AdjustScoreRequest) (db.CredibilityLedger, error)
+	GetLedger(userID uuid.UUID, page, limit int) ([]db.GetCredibilityLedgerRow, error)
+}
+
+type service struct {
+	repo       CredibilityRepository
+	logService auditlogs.LogsService
+	weights    map[pkg.CredibilityEvent]int
+	promoteAt  int
+	suspendAt  int
+}
+
+// NewCredibilityService scores events with the CREDIBILITY_WEIGHT_* settings.
+// A citizen on probation becomes regular once their score reaches
+// CREDIBILITY_PROMOTE_SCORE, and is suspended once it drops to
+// CREDIBILITY_SUSPEND_SCORE.
+func NewCredibilityService(repo CredibilityRepository, logService auditlogs.LogsService) CredibilityService {
+	viper.SetDefault("CREDIBILITY_WEIGHT_REPORT_RESOLVED", 5)
+	viper.SetDefault("CREDIBILITY_WEIGHT_UPVOTE_RECEIVED", 1)
+	viper.SetDefault("CREDIBILITY_WEIGHT_SPAM_CONFIRMED", -20)
+	viper.SetDefault("CREDIBILITY_WEIGHT_REPORT_HIDDEN", -10)
+	viper.SetDefault("CREDIBILITY_PROMOTE_SCORE", 70)
+	viper.SetDefault("CREDIBILITY_SUSPEND_SCORE", 10)
+
+	return &service{
+		repo:       repo,
+		logService: logService,
+		weights: map[pkg.CredibilityEvent]int{
+			pkg.CredibilityReportResolved: viper.GetInt("CREDIBILITY_WEIGHT_REPORT_RESOLVED"),
+			pkg.CredibilityUpvoteReceived: viper.GetInt("CREDIBILITY_WEIGHT_UPVOTE_RECEIVED"),
+			pkg.CredibilitySpamConfirmed:  viper.GetInt("CREDIBILITY_WEIGHT_SPAM_CONFIRMED"),
+			pkg.CredibilityReportHidden:   viper.GetInt("CREDIBILITY_WEIGHT_REPORT_HIDDEN"),
+		},
+		promoteAt: viper.GetInt("CREDIBILITY_PROMOTE_SCORE"),
+		suspendAt: viper.GetInt("CREDIBILITY_SUSPEND_SCORE"),
+	}
+}
+
+// Record scores event with its configured weight. Events with a zero weight,
+// events already scored and events of deleted users leave the score alone.
+func (s *service) Record(event Event) error {
+	delta := s.weights[event.Type]
+	if delta == 0 || event.UserID == uuid.Nil {
+		return nil
+	}
+
+	entry, err := s.apply(event, delta, "")
+	if err != nil {
+		if errors.Is(err, ErrAlreadyScored) || errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if entry.StatusAfter != entry.StatusBefore {
+		log.Printf("credibility: user %s moved from %s to %s at score %d after %s",
+			entry.UserID, entry.StatusBefore, entry.StatusAfter, entry.ScoreAfter, entry.Event)
+	}
+
+	return nil
+}
+
+// Adjust changes the score of a user by hand, for cases the events do not
+// cover. The usual thresholds apply.
+func (s *service) Adjust(actorID uuid.UUID, userID uuid.UUID, req AdjustScoreRequest) (db.CredibilityLedger, error) {
+	entry, err := s.apply(Event{
+		Type:    pkg.CredibilityManual,
+		UserID:  userID,
+		ActorID: actorID,
+	}, req.Delta, req.Reason)
+	if err != nil {
+		return db.CredibilityLedger{}, err
+	}
+
+	// log manual adjustment
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"delta":         entry.Delta,
+			"reason":        req.Reason,
+			"score_before":  entry.ScoreBefore,
+			"score_after":   entry.ScoreAfter,
+			"status_before": entry.StatusBefore,
+			"status_after":  entry.StatusAfter,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityUsers),
+			Action:      string(pkg.LogTypeUpdate),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    userID,
+			PerformedBy: actorID,
+		})
+	}()
+
+	return entry, nil
+}
+
+func (s *service) GetLedger(userID uuid.UUID, page, limit int) ([]db.GetCredibilityLedgerRow, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if limit <= 0 {
+		limit = 20
+	}
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	return s.repo.GetLedger(db.GetCredibilityLedgerParams{
+		UserID:      userID,
+		OffsetCount: int32((page - 1) * limit),
+		LimitCount:  int32(limit),
+	})
+}
+
+func (s *service) apply(event Event, delta int, reason string) (db.CredibilityLedger, error) {
+	entry, err := s.repo.Apply(event.UserID, event.ReportID, overlaps[event.Type], func(current db.GetUserCredibilityForUpdateRow, taken int) db.CreateCredibilityEntryParams {
+		// the ledger still records the event when an earlier penalty
+		// already covers it, so it is not scored again later
+		if taken < 0 && delta < 0 {
+			delta = min(0, delta-taken)
+		}
+
+		scoreBefore := defaultScore
+		if current.CredibilityScore.Valid {
+			scoreBefore = int(current.CredibilityScore.Int16)
+		}
+
+		statusBefore := defaultStatus
+		if current.Status.Valid {
+			statusBefore = pkg.UserStatus(current.Status.String)
+		}
+
+		scoreAfter := clamp(scoreBefore + delta)
+		statusAfter := statusBefore
+
+		// officials and admins are scored, but their status is managed by hand
+		if current.RoleName.String == string(pkg.RoleCitizen) {
+			statusAfter = s.nextStatus(statusBefore, scoreAfter)
+		}
+
+		return db.CreateCredibilityEntryParams{
+			UserID:       event.UserID,
+			Event:        string(event.Type),
+			Delta:        int16(scoreAfter - scoreBefore),
+			ScoreBefore:  int16(scoreBefore),
+			ScoreAfter:   int16(scoreAfter),
+			StatusBefore: string(statusBefore),
+			StatusAfter:  string(statusAfter),
+			ReportID:     pgtype.UUID{Bytes: event.ReportID, Valid: event.ReportID != uuid.Nil},
+			ActorID:      pgtype.UUID{Bytes: event.ActorID, Valid: event.ActorID != uuid.Nil},
+			Reason:       pgtype.Text{String: reason, Valid: reason != ""},
+		}
+	})
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return db.CredibilityLedger{}, ErrUserNotFound
+		}
+		return db.CredibilityLedger{}, err
+	}
+
+	return entry, nil
+}
+
+// nextStatus suspends users whose score dropped to the suspend threshold and
+// promotes users on probation who reached the promote threshold. Lifting a
+// suspension is left to an admin.
+func (s *service) nextStatus(status pkg.UserStatus, score int) pkg.UserStatus {
+	switch {
+	case status == pkg.UserSuspended:
+		return status
+	case score <= s.suspendAt:
+		return pkg.UserSuspended
+	case status == pkg.UserProbation && score >= s.promoteAt:
+		return pkg.UserRegular
+	default:
+		return status
+	}
+}
+
+func clamp(score int) int {
+	return max(minScore, min(maxScore, score))
+}
@@ -0,0 +1,50 @@
+package mail
+
+import (
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MailRepository interface {
+	CreateOutboxEmail(arg db.CreateOutboxEmailParams) (uuid.UUID, error)
+	ClaimDueEmails(arg db.ClaimDueEmailsParams) ([]db.EmailOutbox, error)
+	MarkEmailSent(id uuid.UUID) error
+	MarkEmailFailed(arg db.MarkEmailFailedParams) error
+	GetOutboxEmails(arg db.GetOutboxEmailsParams) ([]db.GetOutboxEmailsRow, error)
+	RetryOutboxEmail(id uuid.UUID) (uuid.UUID, error)
+}
+
+type repository struct {
+	db *db.Queries
+}
+
+func NewMailRepository(pool *pgxpool.Pool) MailRepository {
+	return &repository{db: db.New(pool)}
+}
+
+func (r *repository) CreateOutboxEmail(arg db.CreateOutboxEmailParams) (uuid.UUID, error) {
+	return r.db.CreateOutboxEmail(context.Background(), arg)
+}
+
+func (r *repository) ClaimDueEmails(arg db.ClaimDueEmailsParams) ([]db.EmailOutbox, error) {
+	return r.db.ClaimDueEmails(context.Background(), arg)
+}
+
+func (r *repository) MarkEmailSent(id uuid.UUID) error {
+	return r.db.MarkEmailSent(context.Background(), id)
+}
+
+func (r *repository) MarkEmailFailed(arg db.MarkEmailFailedParams) error {
+	return r.db.MarkEmailFailed(context.Background(), arg)
+}
+
+func (r *repository) GetOutboxEmails(arg db.GetOutboxEmailsParams) ([]db.GetOutboxEmailsRow, error) {
+	return r.db.GetOutboxEmails(context.Background(), arg)
+}
+
+func (r *repository) RetryOutboxEmail(id uuid.UUID) (uuid.UUID, error) {
+	return r.db.RetryOutboxEmail(context.Background(), id)
+}
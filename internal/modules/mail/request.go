@@ -0,0 +1,7 @@
+package mail
+
+type OutboxRequest struct {
+	Page   int    `json:"page"`
+	Limit  int    `json:"limit"`
+	Status string `json:"status"` // pending, sent or failed, empty for all
+}
@@ -0,0 +1,294 @@
+package mail
+
+import (
+	"encoding/json"
+	"errors"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/internal/modules/auditlogs"
+	"hubku/lapor_warga_be_v2/pkg"
+	"log"
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/spf13/viper"
+)
+
+var (
+	ErrEmailNotFound       = errors.New("failed email not found")
+	ErrInvalidRecipient    = errors.New("invalid email address")
+	ErrInvalidOutboxStatus = errors.New("invalid outbox status")
+)
+
+const (
+	// deliverBatchSize is the number of emails claimed per query.
+	deliverBatchSize = 10
+	sendTimeout      = time.Minute
+	// deliverLease is how long claimed emails are reserved for the worker
+	// sending them, so it must outlast sending the whole batch one by one.
+	deliverLease = deliverBatchSize*sendTimeout + time.Minute
+	// maxRetryBackoff caps the wait between two attempts.
+	maxRetryBackoff = 6 * time.Hour
+)
+
+type MailService interface {
+	Queue(to, template, locale string, data interface{}) (uuid.UUID, error)
+	Deliver() (int, error)
+	GetOutbox(req OutboxRequest) ([]db.GetOutboxEmailsRow, error)
+	Retry(currentUserID uuid.UUID, id uuid.UUID) error
+}
+
+type service struct {
+	repo       MailRepository
+	mailer     Mailer
+	templates  *Templates
+	logService auditlogs.LogsService
+	enckey     []byte
+
+	maxAttempts  int32
+	retryBackoff time.Duration
+	// wake starts a delivery run before the next tick
+	wake chan struct{}
+}
+
+// NewMailService returns a service that queues emails in the outbox and
+// delivers them from a background goroutine, every MAIL_OUTBOX_INTERVAL and
+// right after an email is queued. A failed email is retried after
+// MAIL_RETRY_BACKOFF, doubling with every attempt, until it failed
+// MAIL_MAX_ATTEMPTS times.
+func NewMailService(repo MailRepository, mailer Mailer, templates *Templates, logService auditlogs.LogsService, encKey string) MailService {
+	viper.SetDefault("MAIL_OUTBOX_INTERVAL", "30s")
+	viper.SetDefault("MAIL_MAX_ATTEMPTS", 8)
+	viper.SetDefault("MAIL_RETRY_BACKOFF", "1m")
+
+	s := &service{
+		repo:       repo,
+		mailer:     mailer,
+		templates:  templates,
+		logService: logService,
+		enckey:     []byte(encKey),
+
+		maxAttempts:  viper.GetInt32("MAIL_MAX_ATTEMPTS"),
+		retryBackoff: viper.GetDuration("MAIL_RETRY_BACKOFF"),
+		wake:         make(chan struct{}, 1),
+	}
+
+	if s.maxAttempts <= 0 {
+		s.maxAttempts = 8
+	}
+	if s.retryBackoff <= 0 {
+		s.retryBackoff = time.Minute
+	}
+
+	interval := viper.GetDuration("MAIL_OUTBOX_INTERVAL")
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	go s.run(interval)
+
+	return s
+}
+
+// Queue renders the template in the locale closest to locale and stores the
+// email in the outbox. The recipient and the bodies are stored encrypted.
+func (s *service) Queue(to, template, locale string, data interface{}) (uuid.UUID, error) {
+	address, err := mail.ParseAddress(to)
+	if err != nil {
+		return uuid.Nil, ErrInvalidRecipient
+	}
+
+	locale = s.templates.Locale(locale)
+
+	msg, err := s.templates.Render(template, locale, data)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	recipientEnc, err := pkg.Encrypt([]byte(address.Address), s.enckey)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	textEnc, err := pkg.Encrypt([]byte(msg.Text), s.enckey)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	htmlEnc, err := pkg.Encrypt([]byte(msg.HTML), s.enckey)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	id, err := s.repo.CreateOutboxEmail(db.CreateOutboxEmailParams{
+		RecipientEnc: recipientEnc,
+		Template:     template,
+		Locale:       locale,
+		Subject:      msg.Subject,
+		TextBodyEnc:  textEnc,
+		HtmlBodyEnc:  htmlEnc,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	s.nudge()
+
+	return id, nil
+}
+
+// Deliver sends every due email in the outbox and returns how many were
+// sent. Emails that fail are scheduled for another attempt.
+func (s *service) Deliver() (int, error) {
+	sent := 0
+
+	for {
+		emails, err := s.repo.ClaimDueEmails(db.ClaimDueEmailsParams{
+			LeaseUntil: time.Now().Add(deliverLease),
+			LimitCount: deliverBatchSize,
+		})
+		if err != nil {
+			return sent, err
+		}
+
+		for _, email := range emails {
+			if err := s.send(email); err != nil {
+				if err := s.repo.MarkEmailFailed(db.MarkEmailFailedParams{
+					LastError:     err.Error(),
+					NextAttemptAt: time.Now().Add(s.backoff(email.Attempts)),
+					MaxAttempts:   s.maxAttempts,
+					ID:            email.ID,
+				}); err != nil {
+					return sent, err
+				}
+				continue
+			}
+
+			if err := s.repo.MarkEmailSent(email.ID); err != nil {
+				return sent, err
+			}
+			sent++
+		}
+
+		if len(emails) < deliverBatchSize {
+			return sent, nil
+		}
+	}
+}
+
+// GetOutbox lists queued emails, newest first, without their recipient and
+// bodies.
+func (s *service) GetOutbox(req OutboxRequest) ([]db.GetOutboxEmailsRow, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	switch req.Status {
+	case "", "pending", "sent", "failed":
+	default:
+		return nil, ErrInvalidOutboxStatus
+	}
+
+	return s.repo.GetOutboxEmails(db.GetOutboxEmailsParams{
+		Status: pgtype.Text{
+			String: req.Status,
+			Valid:  req.Status != "",
+		},
+		LimitCount:  int32(req.Limit),
+		OffsetCount: int32((req.Page - 1) * req.Limit),
+	})
+}
+
+// Retry queues a failed email again with a fresh number of attempts.
+func (s *service) Retry(currentUserID uuid.UUID, id uuid.UUID) error {
+	if _, err := s.repo.RetryOutboxEmail(id); err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return ErrEmailNotFound
+		}
+		return err
+	}
+
+	s.nudge()
+
+	// log retry
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"status": "pending",
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityEmails),
+			Action:      string(pkg.LogTypeUpdate),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    id,
+			PerformedBy: currentUserID,
+		})
+	}()
+
+	return nil
+}
+
+func (s *service) send(email db.EmailOutbox) error {
+	to, err := pkg.Decrypt(email.RecipientEnc, s.enckey)
+	if err != nil {
+		return err
+	}
+
+	text, err := pkg.Decrypt(email.TextBodyEnc, s.enckey)
+	if err != nil {
+		return err
+	}
+
+	html, err := pkg.Decrypt(email.HtmlBodyEnc, s.enckey)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := pkg.CreateContext(sendTimeout)
+	defer cancel()
+
+	return s.mailer.Send(ctx, Message{
+		To:      string(to),
+		Subject: email.Subject,
+		Text:    string(text),
+		HTML:    string(html),
+	})
+}
+
+// backoff returns how long to wait after the attempts+1th failure.
+func (s *service) backoff(attempts int32) time.Duration {
+	wait := s.retryBackoff
+	for i := int32(0); i < attempts && wait < maxRetryBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxRetryBackoff)
+}
+
+// nudge wakes the worker without waiting for it.
+func (s *service) nudge() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *service) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		}
+
+		if _, err := s.Deliver(); err != nil {
+			log.Printf("Failed to deliver outbox emails: %v", err)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package mail
+
+import (
+	"errors"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// memoryOutbox is a MailRepository that follows the outbox queries in memory.
+type memoryOutbox struct {
+	mu     sync.Mutex
+	emails map[uuid.UUID]*db.EmailOutbox
+	leases []time.Time
+}
+
+func newMemoryOutbox() *memoryOutbox {
+	return &memoryOutbox{emails: map[uuid.UUID]*db.EmailOutbox{}}
+}
+
+func (m *memoryOutbox) CreateOutboxEmail(arg db.CreateOutboxEmailParams) (uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := uuid.New()
+	m.emails[id] = &db.EmailOutbox{
+		ID:            id,
+		RecipientEnc:  arg.RecipientEnc,
+		Template:      arg.Template,
+		Locale:        arg.Locale,
+		Subject:       arg.Subject,
+		TextBodyEnc:   arg.TextBodyEnc,
+		HtmlBodyEnc:   arg.HtmlBodyEnc,
+		Status:        "pending",
+		NextAttemptAt: time.Now(),
+	}
+	return id, nil
+}
+
+func (m *memoryOutbox) ClaimDueEmails(arg db.ClaimDueEmailsParams) ([]db.EmailOutbox, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.leases = append(m.leases, arg.LeaseUntil)
+
+	var due []*db.EmailOutbox
+	for _, email := range m.emails {
+		if email.Status == "pending" && !email.NextAttemptAt.After(time.Now()) {
+			due = append(due, email)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+
+	claimed := []db.EmailOutbox{}
+	for _, email := range due {
+		if len(claimed) == int(arg.LimitCount) {
+			break
+		}
+		email.NextAttemptAt = arg.LeaseUntil
+		claimed = append(claimed, *email)
+	}
+	return claimed, nil
+}
+
+func (m *memoryOutbox) MarkEmailSent(id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	email := m.emails[id]
+	email.Status = "sent"
+	email.Attempts++
+	email.LastError = pgtype.Text{}
+	email.SentAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (m *memoryOutbox) MarkEmailFailed(arg db.MarkEmailFailedParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	email := m.emails[arg.ID]
+	email.Attempts++
+	email.LastError = pgtype.Text{String: arg.LastError, Valid: true}
+	email.NextAttemptAt = arg.NextAttemptAt
+	if email.Attempts >= arg.MaxAttempts {
+		email.Status = "failed"
+	}
+	return nil
+}
+
+func (m *memoryOutbox) GetOutboxEmails(arg db.GetOutboxEmailsParams) ([]db.GetOutboxEmailsRow, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *memoryOutbox) RetryOutboxEmail(id uuid.UUID) (uuid.UUID, error) {
+	return uuid.Nil, errors.New("not implemented")
+}
+
+// get returns a copy of an email as stored.
+func (m *memoryOutbox) get(id uuid.UUID) db.EmailOutbox {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return *m.emails[id]
+}
+
+// makeDue moves the next attempt of an email to now, as if its backoff passed.
+func (m *memoryOutbox) makeDue(id uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.emails[id].NextAttemptAt = time.Now()
+}
+
+// newTestService returns a service without its background worker, so the
+// test decides when emails are delivered.
+func newTestService(t *testing.T) (*service, *memoryOutbox, *MemoryMailer) {
+	t.Helper()
+
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, mailer := newMemoryOutbox(), NewMemoryMailer()
+	return &service{
+		repo:         repo,
+		mailer:       mailer,
+		templates:    templates,
+		enckey:       []byte("0123456789abcdef0123456789abcdef"),
+		maxAttempts:  3,
+		retryBackoff: time.Minute,
+		wake:         make(chan struct{}, 1),
+	}, repo, mailer
+}
+
+var verificationData = map[string]interface{}{
+	"Name":      "Sari",
+	"Link":      "https://lapor.example/verify?token=abc",
+	"ExpiresIn": "24 jam",
+}
+
+func TestQueueAndDeliver(t *testing.T) {
+	s, repo, mailer := newTestService(t)
+
+	id, err := s.Queue("Sari <sari@example.com>", "verification", "id-ID", verificationData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := repo.get(id)
+	if stored.Locale != "id" || stored.Subject != "Verifikasi alamat email Anda" {
+		t.Fatalf("queued %s email with subject %q", stored.Locale, stored.Subject)
+	}
+	if strings.Contains(string(stored.RecipientEnc), "sari@example.com") {
+		t.Fatal("recipient stored in plain text")
+	}
+
+	sent, err := s.Deliver()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 {
+		t.Fatalf("sent %d emails, want 1", sent)
+	}
+
+	messages := mailer.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("mailer got %d messages, want 1", len(messages))
+	}
+	msg := messages[0]
+	if msg.To != "sari@example.com" || msg.Subject != "Verifikasi alamat email Anda" {
+		t.Fatalf("sent %+v", msg)
+	}
+	if !strings.Contains(msg.Text, verificationData["Link"].(string)) || !strings.Contains(msg.HTML, "Sari") {
+		t.Fatal("bodies were not rendered with the data")
+	}
+
+	if status := repo.get(id).Status; status != "sent" {
+		t.Fatalf("status %q after delivery", status)
+	}
+
+	// nothing is due any more
+	if sent, err := s.Deliver(); err != nil || sent != 0 {
+		t.Fatalf("second delivery sent %d, %v", sent, err)
+	}
+}
+
+func TestQueueRejectsInvalidRecipient(t *testing.T) {
+	s, _, _ := newTestService(t)
+
+	if _, err := s.Queue("not an address", "verification", "en", verificationData); !errors.Is(err, ErrInvalidRecipient) {
+		t.Fatalf("got %v, want ErrInvalidRecipient", err)
+	}
+}
+
+func TestDeliverRetriesWithBackoff(t *testing.T) {
+	s, repo, mailer := newTestService(t)
+
+	id, err := s.Queue("sari@example.com", "verification", "en", verificationData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mailer.FailWith(errors.New("421 try again later"))
+
+	before := time.Now()
+	if sent, err := s.Deliver(); err != nil || sent != 0 {
+		t.Fatalf("failing delivery sent %d, %v", sent, err)
+	}
+
+	email := repo.get(id)
+	if email.Status != "pending" || email.Attempts != 1 || email.LastError.String != "421 try again later" {
+		t.Fatalf("after the first failure: %s, %d attempts, %q", email.Status, email.Attempts, email.LastError.String)
+	}
+	if wait := email.NextAttemptAt.Sub(before); wait < time.Minute || wait > time.Minute+time.Second {
+		t.Fatalf("first retry in %s, want 1m", wait)
+	}
+
+	// not due yet, so not claimed again
+	if sent, err := s.Deliver(); err != nil || sent != 0 || repo.get(id).Attempts != 1 {
+		t.Fatalf("retried before the backoff passed")
+	}
+
+	repo.makeDue(id)
+	before = time.Now()
+	if _, err := s.Deliver(); err != nil {
+		t.Fatal(err)
+	}
+	email = repo.get(id)
+	if wait := email.NextAttemptAt.Sub(before); email.Attempts != 2 || wait < 2*time.Minute || wait > 2*time.Minute+time.Second {
+		t.Fatalf("second retry in %s after %d attempts, want 2m after 2", wait, email.Attempts)
+	}
+
+	// the third failure reaches maxAttempts
+	repo.makeDue(id)
+	if _, err := s.Deliver(); err != nil {
+		t.Fatal(err)
+	}
+	if email := repo.get(id); email.Status != "failed" || email.Attempts != 3 {
+		t.Fatalf("after the last attempt: %s, %d attempts", email.Status, email.Attempts)
+	}
+
+	mailer.FailWith(nil)
+	repo.makeDue(id)
+	if sent, err := s.Deliver(); err != nil || sent != 0 || len(mailer.Messages()) != 0 {
+		t.Fatal("a failed email was sent again")
+	}
+}
+
+func TestDeliverLeaseCoversTheBatch(t *testing.T) {
+	s, repo, _ := newTestService(t)
+
+	for i := 0; i < deliverBatchSize+1; i++ {
+		if _, err := s.Queue("sari@example.com", "verification", "en", verificationData); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	before := time.Now()
+	sent, err := s.Deliver()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != deliverBatchSize+1 {
+		t.Fatalf("sent %d emails, want %d", sent, deliverBatchSize+1)
+	}
+
+	// a batch claimed at once must stay leased while every email in it
+	// uses up its send timeout
+	for _, lease := range repo.leases {
+		if lease.Sub(before) < deliverBatchSize*sendTimeout {
+			t.Fatalf("emails leased for %s, sending a batch can take %s", lease.Sub(before), deliverBatchSize*sendTimeout)
+		}
+	}
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	s, _, _ := newTestService(t)
+
+	if got := s.backoff(0); got != time.Minute {
+		t.Fatalf("backoff after one failure %s", got)
+	}
+	if got := s.backoff(30); got != maxRetryBackoff {
+		t.Fatalf("backoff after many failures %s, want %s", got, maxRetryBackoff)
+	}
+}
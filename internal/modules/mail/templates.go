@@ -0,0 +1,129 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/spf13/viper"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+// Templates renders the localized emails in templates/<locale>/. Each email
+// has a <name>.txt with a "subject" block followed by the plain text body,
+// and a <name>.html with a "content" block placed in the layout.html of its
+// locale.
+type Templates struct {
+	fallback string
+	text     map[string]*texttemplate.Template
+	html     map[string]*htmltemplate.Template
+}
+
+// LoadTemplates parses every embedded template. Emails in a locale without
+// templates are written in MAIL_DEFAULT_LOCALE.
+func LoadTemplates() (*Templates, error) {
+	viper.SetDefault("MAIL_DEFAULT_LOCALE", "id")
+	fallback := viper.GetString("MAIL_DEFAULT_LOCALE")
+
+	t := &Templates{
+		fallback: fallback,
+		text:     make(map[string]*texttemplate.Template),
+		html:     make(map[string]*htmltemplate.Template),
+	}
+
+	files, err := fs.Glob(templateFiles, "templates/*/*.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		locale := path.Base(path.Dir(file))
+		name := strings.TrimSuffix(path.Base(file), ".txt")
+		key := locale + "/" + name
+
+		text, err := texttemplate.New(path.Base(file)).Option("missingkey=error").ParseFS(templateFiles, file)
+		if err != nil {
+			return nil, err
+		}
+
+		html, err := htmltemplate.New("layout.html").Option("missingkey=error").ParseFS(
+			templateFiles,
+			path.Join("templates", locale, "layout.html"),
+			path.Join("templates", locale, name+".html"),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		t.text[key] = text
+		t.html[key] = html
+	}
+
+	if !t.hasLocale(fallback) {
+		return nil, errors.New("no email templates for locale " + fallback)
+	}
+
+	return t, nil
+}
+
+// Locale returns the locale an email asked for in requested is written in.
+// Regional variants use their language ("en-US" is "en").
+func (t *Templates) Locale(requested string) string {
+	language := strings.ToLower(requested)
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+
+	if t.hasLocale(language) {
+		return language
+	}
+
+	return t.fallback
+}
+
+func (t *Templates) hasLocale(locale string) bool {
+	for key := range t.text {
+		if strings.HasPrefix(key, locale+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Render fills the template name of locale with data. The recipient of the
+// returned message is left empty.
+func (t *Templates) Render(name, locale string, data interface{}) (Message, error) {
+	key := t.Locale(locale) + "/" + name
+
+	text, ok := t.text[key]
+	if !ok {
+		return Message{}, ErrUnknownTemplate
+	}
+
+	var subject, body, html bytes.Buffer
+
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := text.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+	if err := t.html[key].ExecuteTemplate(&html, "layout", data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(body.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
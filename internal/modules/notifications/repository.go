@@ -0,0 +1,45 @@
+package notifications
+
+import (
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type NotificationsRepository interface {
+	CreateNotifications(arg db.CreateNotificationsParams) ([]db.Notification, error)
+	GetNotifications(arg db.GetNotificationsParams) ([]db.GetNotificationsRow, error)
+	CountUnread(userID uuid.UUID) (int64, error)
+	MarkRead(arg db.MarkNotificationReadParams) (uuid.UUID, error)
+	MarkAllRead(userID uuid.UUID) (int64, error)
+}
+
+type repository struct {
+	db *db.Queries
+}
+
+func NewNotificationsRepository(pool *pgxpool.Pool) NotificationsRepository {
+	return &repository{db: db.New(pool)}
+}
+
+func (r *repository) CreateNotifications(arg db.CreateNotificationsParams) ([]db.Notification, error) {
+	return r.db.CreateNotifications(context.Background(), arg)
+}
+
+func (r *repository) GetNotifications(arg db.GetNotificationsParams) ([]db.GetNotificationsRow, error) {
+	return r.db.GetNotifications(context.Background(), arg)
+}
+
+func (r *repository) CountUnread(userID uuid.UUID) (int64, error) {
+	return r.db.CountUnreadNotifications(context.Background(), userID)
+}
+
+func (r *repository) MarkRead(arg db.MarkNotificationReadParams) (uuid.UUID, error) {
+	return r.db.MarkNotificationRead(context.Background(), arg)
+}
+
+func (r *repository) MarkAllRead(userID uuid.UUID) (int64, error) {
+	return r.db.MarkAllNotificationsRead(context.Background(), userID)
+}
@@ -0,0 +1,7 @@
+package notifications
+
+type NotificationsRequest struct {
+	Cursor     string `json:"cursor"`
+	Limit      int    `json:"limit"`
+	UnreadOnly bool   `json:"unread_only"`
+}
@@ -0,0 +1,178 @@
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/internal/modules/push"
+	"hubku/lapor_warga_be_v2/pkg"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// Event is something that happened to a report which the recipients should
+// hear about.
+type Event struct {
+	Type       pkg.NotificationType
+	Recipients []uuid.UUID
+	// ReportID is the report the event is about, uuid.Nil for none.
+	ReportID uuid.UUID
+	// ActorID is the user who caused the event, uuid.Nil when the system
+	// did. The actor is never notified of their own action.
+	ActorID uuid.UUID
+	Data    map[string]interface{}
+}
+
+type NotificationPage struct {
+	Notifications []db.GetNotificationsRow `json:"notifications"`
+	NextCursor    string                   `json:"next_cursor,omitempty"`
+}
+
+// notificationCursor is the position of the last notification of a page.
+type notificationCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+type NotificationsService interface {
+	Notify(event Event) ([]db.Notification, error)
+	GetNotifications(currentUserID uuid.UUID, req NotificationsRequest) (NotificationPage, error)
+	CountUnread(currentUserID uuid.UUID) (int64, error)
+	MarkRead(currentUserID uuid.UUID, id uuid.UUID) error
+	MarkAllRead(currentUserID uuid.UUID) (int64, error)
+}
+
+type service struct {
+	repo        NotificationsRepository
+	pushService push.PushService
+}
+
+func NewNotificationsService(repo NotificationsRepository, pushService push.PushService) NotificationsService {
+	return &service{repo: repo, pushService: pushService}
+}
+
+// Notify stores one notification of event for each distinct recipient other
+// than the actor, and pushes them to the recipients' devices in the
+// background.
+func (s *service) Notify(event Event) ([]db.Notification, error) {
+	seen := make(map[uuid.UUID]bool, len(event.Recipients))
+	recipients := make([]uuid.UUID, 0, len(event.Recipients))
+
+	for _, id := range event.Recipients {
+		if id == uuid.Nil || id == event.ActorID || seen[id] {
+			continue
+		}
+		seen[id] = true
+		recipients = append(recipients, id)
+	}
+
+	if len(recipients) == 0 {
+		return []db.Notification{}, nil
+	}
+
+	data := event.Data
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateNotifications(db.CreateNotificationsParams{
+		Type: string(event.Type),
+		ReportID: pgtype.UUID{
+			Bytes: event.ReportID,
+			Valid: event.ReportID != uuid.Nil,
+		},
+		ActorID: pgtype.UUID{
+			Bytes: event.ActorID,
+			Valid: event.ActorID != uuid.Nil,
+		},
+		Data:    json.RawMessage(raw),
+		UserIds: recipients,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		if _, err := s.pushService.Push(created); err != nil {
+			log.Printf("failed to push %s notifications: %v", event.Type, err)
+		}
+	}()
+
+	return created, nil
+}
+
+// GetNotifications returns a page of the user's notifications, newest first.
+func (s *service) GetNotifications(currentUserID uuid.UUID, req NotificationsRequest) (NotificationPage, error) {
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	arg := db.GetNotificationsParams{
+		UserID:     currentUserID,
+		UnreadOnly: req.UnreadOnly,
+		// fetch one extra row to know whether there is a next page
+		LimitCount: int32(req.Limit + 1),
+	}
+
+	if req.Cursor != "" {
+		var position notificationCursor
+		if err := pkg.DecodeCursor(req.Cursor, &position); err != nil {
+			return NotificationPage{}, err
+		}
+		arg.CursorCreatedAt = pgtype.Timestamptz{Time: position.CreatedAt, Valid: true}
+		arg.CursorID = pgtype.UUID{Bytes: position.ID, Valid: true}
+	}
+
+	rows, err := s.repo.GetNotifications(arg)
+	if err != nil {
+		return NotificationPage{}, err
+	}
+
+	page := NotificationPage{Notifications: rows}
+
+	if len(rows) > req.Limit {
+		page.Notifications = rows[:req.Limit]
+		last := page.Notifications[req.Limit-1]
+		page.NextCursor = pkg.EncodeCursor(notificationCursor{
+			CreatedAt: last.CreatedAt.Time,
+			ID:        last.ID,
+		})
+	}
+
+	return page, nil
+}
+
+func (s *service) CountUnread(currentUserID uuid.UUID) (int64, error) {
+	return s.repo.CountUnread(currentUserID)
+}
+
+// MarkRead marks one of the user's notifications as read. Marking a read
+// notification again keeps its first read time.
+func (s *service) MarkRead(currentUserID uuid.UUID, id uuid.UUID) error {
+	if _, err := s.repo.MarkRead(db.MarkNotificationReadParams{
+		ID:     id,
+		UserID: currentUserID,
+	}); err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return ErrNotificationNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// MarkAllRead marks every unread notification of the user as read and
+// returns how many it marked.
+func (s *service) MarkAllRead(currentUserID uuid.UUID) (int64, error) {
+	return s.repo.MarkAllRead(currentUserID)
+}
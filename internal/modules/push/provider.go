@@ -0,0 +1,46 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// ErrInvalidToken is returned by a provider for device tokens that will never
+// accept messages again, such as tokens of uninstalled apps.
+var ErrInvalidToken = errors.New("invalid device token")
+
+// Message is a push notification to a single device.
+type Message struct {
+	Token string
+	Title string
+	Body  string
+	// Data is handed to the app with the notification.
+	Data map[string]string
+}
+
+// PushProvider delivers push notifications to devices.
+type PushProvider interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewPushProvider builds the provider selected by PUSH_DRIVER ("fcm" or
+// "memory"). The memory provider never leaves the process and is meant for
+// development and tests.
+func NewPushProvider() (PushProvider, error) {
+	viper.SetDefault("PUSH_DRIVER", "memory")
+
+	switch driver := viper.GetString("PUSH_DRIVER"); driver {
+	case "fcm":
+		return NewFCMProvider(FCMConfig{
+			CredentialsFile: viper.GetString("FCM_CREDENTIALS_FILE"),
+			ProjectID:       viper.GetString("FCM_PROJECT_ID"),
+		})
+	case "memory":
+		return NewMemoryProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown push driver: %s", driver)
+	}
+}
@@ -0,0 +1,224 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	fcmEndpoint = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+	fcmScope    = "https://www.googleapis.com/auth/firebase.messaging"
+	googleToken = "https://oauth2.googleapis.com/token"
+)
+
+// fcmInvalidTokenCodes are the FCM error codes after which a token should
+// not be used again. INVALID_ARGUMENT is not among them, as FCM also returns
+// it for malformed messages; it only counts when the token is the field at
+// fault.
+var fcmInvalidTokenCodes = map[string]bool{
+	"UNREGISTERED":       true,
+	"SENDER_ID_MISMATCH": true,
+}
+
+// fcmTokenField is the field a bad request names when the token is invalid.
+const fcmTokenField = "message.token"
+
+type FCMConfig struct {
+	CredentialsFile string // service account key (JSON) of the Firebase project
+	ProjectID       string // optional, taken from the credentials when empty
+}
+
+// serviceAccount is the part of a Google service account key we need.
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCMProvider sends push notifications through the Firebase Cloud Messaging
+// HTTP v1 API, authenticated as a service account.
+type FCMProvider struct {
+	endpoint string
+	account  serviceAccount
+	key      *rsa.PrivateKey
+	client   *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func NewFCMProvider(cfg FCMConfig) (*FCMProvider, error) {
+	if cfg.CredentialsFile == "" {
+		return nil, errors.New("FCM_CREDENTIALS_FILE is required")
+	}
+
+	raw, err := os.ReadFile(cfg.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var account serviceAccount
+	if err := json.Unmarshal(raw, &account); err != nil {
+		return nil, fmt.Errorf("invalid FCM credentials: %w", err)
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FCM credentials: %w", err)
+	}
+
+	if account.TokenURI == "" {
+		account.TokenURI = googleToken
+	}
+
+	projectID := cfg.ProjectID
+	if projectID == "" {
+		projectID = account.ProjectID
+	}
+	if projectID == "" || account.ClientEmail == "" {
+		return nil, errors.New("FCM credentials lack project_id or client_email")
+	}
+
+	return &FCMProvider{
+		endpoint: fmt.Sprintf(fcmEndpoint, projectID),
+		account:  account,
+		key:      key,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (p *FCMProvider) Send(ctx context.Context, msg Message) error {
+	accessToken, err := p.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token": msg.Token,
+			"notification": map[string]string{
+				"title": msg.Title,
+				"body":  msg.Body,
+			},
+			"data": msg.Data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 {
+		return nil
+	}
+
+	var result struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+			Details []struct {
+				ErrorCode       string `json:"errorCode"`
+				FieldViolations []struct {
+					Field string `json:"field"`
+				} `json:"fieldViolations"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	for _, detail := range result.Error.Details {
+		if fcmInvalidTokenCodes[detail.ErrorCode] {
+			return fmt.Errorf("%w: %s", ErrInvalidToken, detail.ErrorCode)
+		}
+		for _, violation := range detail.FieldViolations {
+			if result.Error.Status == "INVALID_ARGUMENT" && violation.Field == fcmTokenField {
+				return fmt.Errorf("%w: %s", ErrInvalidToken, result.Error.Message)
+			}
+		}
+	}
+
+	return fmt.Errorf("fcm: %s (%d %s)", result.Error.Message, resp.StatusCode, result.Error.Status)
+}
+
+// token returns an OAuth access token for the service account, fetching a
+// new one shortly before the cached one expires.
+func (p *FCMProvider) token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Now().Add(time.Minute).Before(p.expiresAt) {
+		return p.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   p.account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   p.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(p.key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+		Error       string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if resp.StatusCode >= 300 || result.AccessToken == "" {
+		return "", fmt.Errorf("fcm: fetching access token failed (%d %s)", resp.StatusCode, result.Error)
+	}
+
+	p.accessToken = result.AccessToken
+	p.expiresAt = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+
+	return p.accessToken, nil
+}
@@ -0,0 +1,161 @@
+package push
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestFCMProvider returns a provider whose token and send endpoints are
+// served by handler.
+func newTestFCMProvider(t *testing.T, send http.HandlerFunc) *FCMProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access", "expires_in": 3600})
+	})
+	mux.HandleFunc("/send", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		send(w, r)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	credentials, _ := json.Marshal(serviceAccount{
+		ProjectID:   "lapor-warga",
+		ClientEmail: "push@lapor-warga.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		TokenURI:    server.URL + "/token",
+	})
+	file := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(file, credentials, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := NewFCMProvider(FCMConfig{CredentialsFile: file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.endpoint = server.URL + "/send"
+	return provider
+}
+
+// fcmError answers like FCM does for a failed send.
+func fcmError(code int, status string, details ...map[string]interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":    code,
+				"message": "request failed",
+				"status":  status,
+				"details": details,
+			},
+		})
+	}
+}
+
+func fcmErrorCode(code string) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":     "type.googleapis.com/google.firebase.fcm.v1.FcmError",
+		"errorCode": code,
+	}
+}
+
+func badField(field string) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":           "type.googleapis.com/google.rpc.BadRequest",
+		"fieldViolations": []map[string]string{{"field": field, "description": "invalid"}},
+	}
+}
+
+func TestFCMProviderSendErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		handler http.HandlerFunc
+		invalid bool
+	}{
+		"unregistered": {
+			fcmError(http.StatusNotFound, "NOT_FOUND", fcmErrorCode("UNREGISTERED")),
+			true,
+		},
+		"sender id mismatch": {
+			fcmError(http.StatusForbidden, "PERMISSION_DENIED", fcmErrorCode("SENDER_ID_MISMATCH")),
+			true,
+		},
+		"malformed token": {
+			fcmError(http.StatusBadRequest, "INVALID_ARGUMENT", fcmErrorCode("INVALID_ARGUMENT"), badField("message.token")),
+			true,
+		},
+		"malformed payload": {
+			fcmError(http.StatusBadRequest, "INVALID_ARGUMENT", fcmErrorCode("INVALID_ARGUMENT"), badField("message.data[0].value")),
+			false,
+		},
+		"invalid argument without details": {
+			fcmError(http.StatusBadRequest, "INVALID_ARGUMENT", fcmErrorCode("INVALID_ARGUMENT")),
+			false,
+		},
+		"unavailable": {
+			fcmError(http.StatusServiceUnavailable, "UNAVAILABLE", fcmErrorCode("UNAVAILABLE")),
+			false,
+		},
+	} {
+		provider := newTestFCMProvider(t, tc.handler)
+
+		err := provider.Send(context.Background(), Message{Token: "device", Title: "t", Body: "b"})
+		if err == nil {
+			t.Errorf("%s: no error", name)
+			continue
+		}
+		if got := errors.Is(err, ErrInvalidToken); got != tc.invalid {
+			t.Errorf("%s: ErrInvalidToken is %v, want %v (%v)", name, got, tc.invalid, err)
+		}
+	}
+}
+
+func TestFCMProviderSend(t *testing.T) {
+	var got struct {
+		Message struct {
+			Token        string            `json:"token"`
+			Notification map[string]string `json:"notification"`
+			Data         map[string]string `json:"data"`
+		} `json:"message"`
+	}
+
+	provider := newTestFCMProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"name":"projects/lapor-warga/messages/1"}`))
+	})
+
+	err := provider.Send(context.Background(), Message{
+		Token: "device",
+		Title: "Laporan diperbarui",
+		Body:  "Status berubah",
+		Data:  map[string]string{"type": "status_changed"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Message.Token != "device" || got.Message.Notification["title"] != "Laporan diperbarui" || got.Message.Data["type"] != "status_changed" {
+		t.Fatalf("sent %+v", got.Message)
+	}
+}
@@ -0,0 +1,58 @@
+package push
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryProvider keeps sent push notifications in memory, for tests. Tokens
+// marked with Invalidate are refused with ErrInvalidToken.
+type MemoryProvider struct {
+	mu       sync.Mutex
+	messages []Message
+	invalid  map[string]bool
+}
+
+func NewMemoryProvider() *MemoryProvider {
+	return &MemoryProvider{invalid: make(map[string]bool)}
+}
+
+func (p *MemoryProvider) Send(ctx context.Context, msg Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.invalid[msg.Token] {
+		return ErrInvalidToken
+	}
+
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+// Messages returns the push notifications sent so far, oldest first.
+func (p *MemoryProvider) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Message(nil), p.messages...)
+}
+
+// Invalidate makes every following Send to one of tokens fail with
+// ErrInvalidToken.
+func (p *MemoryProvider) Invalidate(tokens ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, token := range tokens {
+		p.invalid[token] = true
+	}
+}
+
+// Reset forgets the push notifications sent so far and the invalid tokens.
+func (p *MemoryProvider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.messages = nil
+	p.invalid = make(map[string]bool)
+}
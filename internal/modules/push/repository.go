@@ -0,0 +1,40 @@
+package push
+
+import (
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PushRepository interface {
+	UpsertDeviceToken(arg db.UpsertDeviceTokenParams) (db.DeviceToken, error)
+	DeleteDeviceToken(arg db.DeleteDeviceTokenParams) (int64, error)
+	GetDeviceTokensByUsers(userIDs []uuid.UUID) ([]db.GetDeviceTokensByUsersRow, error)
+	DeleteDeviceTokens(tokens []string) (int64, error)
+}
+
+type repository struct {
+	db *db.Queries
+}
+
+func NewPushRepository(pool *pgxpool.Pool) PushRepository {
+	return &repository{db: db.New(pool)}
+}
+
+func (r *repository) UpsertDeviceToken(arg db.UpsertDeviceTokenParams) (db.DeviceToken, error) {
+	return r.db.UpsertDeviceToken(context.Background(), arg)
+}
+
+func (r *repository) DeleteDeviceToken(arg db.DeleteDeviceTokenParams) (int64, error) {
+	return r.db.DeleteDeviceToken(context.Background(), arg)
+}
+
+func (r *repository) GetDeviceTokensByUsers(userIDs []uuid.UUID) ([]db.GetDeviceTokensByUsersRow, error) {
+	return r.db.GetDeviceTokensByUsers(context.Background(), userIDs)
+}
+
+func (r *repository) DeleteDeviceTokens(tokens []string) (int64, error) {
+	return r.db.DeleteDeviceTokens(context.Background(), tokens)
+}
@@ -0,0 +1,12 @@
+package push
+
+type RegisterDeviceRequest struct {
+	Token    string `json:"token" form:"token" validate:"required,max=4096"`
+	Platform string `json:"platform" form:"platform" validate:"required,oneof=android ios web"`
+	// Locale is the language of the device, e.g. "id" or "en-US"
+	Locale string `json:"locale" form:"locale" validate:"omitempty,max=10"`
+}
+
+type UnregisterDeviceRequest struct {
+	Token string `json:"token" form:"token" validate:"required,max=4096"`
+}
@@ -0,0 +1,129 @@
+package push
+
+import (
+	"errors"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/pkg"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrDeviceNotFound = errors.New("device not found")
+
+// sendTimeout bounds a single push notification to a single device.
+const sendTimeout = 10 * time.Second
+
+type PushService interface {
+	RegisterDevice(currentUserID uuid.UUID, req RegisterDeviceRequest) (db.DeviceToken, error)
+	UnregisterDevice(currentUserID uuid.UUID, req UnregisterDeviceRequest) error
+	Push(notifications []db.Notification) (int, error)
+}
+
+type service struct {
+	repo     PushRepository
+	provider PushProvider
+}
+
+func NewPushService(repo PushRepository, provider PushProvider) PushService {
+	return &service{repo: repo, provider: provider}
+}
+
+// RegisterDevice registers a device token of the current user. A token that
+// was registered by another user before moves to the current user.
+func (s *service) RegisterDevice(currentUserID uuid.UUID, req RegisterDeviceRequest) (db.DeviceToken, error) {
+	return s.repo.UpsertDeviceToken(db.UpsertDeviceTokenParams{
+		UserID:   currentUserID,
+		Token:    req.Token,
+		Platform: req.Platform,
+		Locale:   normalizeLocale(req.Locale),
+	})
+}
+
+// UnregisterDevice removes a device token of the current user, e.g. on
+// logout.
+func (s *service) UnregisterDevice(currentUserID uuid.UUID, req UnregisterDeviceRequest) error {
+	deleted, err := s.repo.DeleteDeviceToken(db.DeleteDeviceTokenParams{
+		Token:  req.Token,
+		UserID: currentUserID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return ErrDeviceNotFound
+	}
+
+	return nil
+}
+
+// Push sends each notification to every device of its user, in the
+// language of the device, and returns how many push notifications were
+// delivered. Tokens the provider refuses as invalid are removed. Other
+// delivery failures are only logged, the notification stays in the
+// notification center.
+func (s *service) Push(notifications []db.Notification) (int, error) {
+	if len(notifications) == 0 {
+		return 0, nil
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(notifications))
+	for _, notification := range notifications {
+		userIDs = append(userIDs, notification.UserID)
+	}
+
+	devices, err := s.repo.GetDeviceTokensByUsers(userIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	devicesByUser := make(map[uuid.UUID][]db.GetDeviceTokensByUsersRow)
+	for _, device := range devices {
+		devicesByUser[device.UserID] = append(devicesByUser[device.UserID], device)
+	}
+
+	sent := 0
+	var invalid []string
+
+	for _, notification := range notifications {
+		for _, device := range devicesByUser[notification.UserID] {
+			title, body := render(device.Locale, notification.Type, notification.Data)
+
+			data := map[string]string{
+				"notification_id": notification.ID.String(),
+				"type":            notification.Type,
+			}
+			if notification.ReportID.Valid {
+				data["report_id"] = uuid.UUID(notification.ReportID.Bytes).String()
+			}
+
+			ctx, cancel := pkg.CreateContext(sendTimeout)
+			err := s.provider.Send(ctx, Message{
+				Token: device.Token,
+				Title: title,
+				Body:  body,
+				Data:  data,
+			})
+			cancel()
+
+			switch {
+			case err == nil:
+				sent++
+			case errors.Is(err, ErrInvalidToken):
+				invalid = append(invalid, device.Token)
+			default:
+				log.Printf("failed to push notification %s to device %s: %v", notification.ID, device.ID, err)
+			}
+		}
+	}
+
+	if len(invalid) > 0 {
+		if _, err := s.repo.DeleteDeviceTokens(invalid); err != nil {
+			return sent, err
+		}
+	}
+
+	return sent, nil
+}
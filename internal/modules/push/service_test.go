@@ -0,0 +1,127 @@
+package push
+
+import (
+	"encoding/json"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/pkg"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// memoryDevices is a PushRepository over a fixed list of devices.
+type memoryDevices struct {
+	mu      sync.Mutex
+	devices []db.GetDeviceTokensByUsersRow
+	deleted []string
+}
+
+func (m *memoryDevices) UpsertDeviceToken(arg db.UpsertDeviceTokenParams) (db.DeviceToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.devices = append(m.devices, db.GetDeviceTokensByUsersRow{
+		ID:       uuid.New(),
+		UserID:   arg.UserID,
+		Token:    arg.Token,
+		Platform: arg.Platform,
+		Locale:   arg.Locale,
+	})
+	return db.DeviceToken{UserID: arg.UserID, Token: arg.Token}, nil
+}
+
+func (m *memoryDevices) DeleteDeviceToken(arg db.DeleteDeviceTokenParams) (int64, error) {
+	return m.DeleteDeviceTokens([]string{arg.Token})
+}
+
+func (m *memoryDevices) GetDeviceTokensByUsers(userIDs []uuid.UUID) ([]db.GetDeviceTokensByUsersRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var rows []db.GetDeviceTokensByUsersRow
+	for _, device := range m.devices {
+		for _, id := range userIDs {
+			if device.UserID == id {
+				rows = append(rows, device)
+				break
+			}
+		}
+	}
+	return rows, nil
+}
+
+func (m *memoryDevices) DeleteDeviceTokens(tokens []string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deleted = append(m.deleted, tokens...)
+
+	kept := m.devices[:0]
+	for _, device := range m.devices {
+		remove := false
+		for _, token := range tokens {
+			remove = remove || device.Token == token
+		}
+		if !remove {
+			kept = append(kept, device)
+		}
+	}
+	removed := int64(len(m.devices) - len(kept))
+	m.devices = kept
+	return removed, nil
+}
+
+func TestPushPrunesInvalidTokens(t *testing.T) {
+	repo := &memoryDevices{}
+	provider := NewMemoryProvider()
+	s := NewPushService(repo, provider)
+
+	citizen, official := uuid.New(), uuid.New()
+	for _, device := range []RegisterDeviceRequest{
+		{Token: "phone", Platform: "android", Locale: "id"},
+		{Token: "old-tablet", Platform: "android", Locale: "en"},
+	} {
+		if _, err := s.RegisterDevice(citizen, device); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.RegisterDevice(official, RegisterDeviceRequest{Token: "laptop", Platform: "web", Locale: "en"}); err != nil {
+		t.Fatal(err)
+	}
+
+	provider.Invalidate("old-tablet")
+
+	data, _ := json.Marshal(map[string]interface{}{"title": "Jalan berlubang", "new_status": "open"})
+	sent, err := s.Push([]db.Notification{
+		{ID: uuid.New(), UserID: citizen, Type: string(pkg.NotificationStatusChanged), Data: data},
+		{ID: uuid.New(), UserID: official, Type: string(pkg.NotificationStatusChanged), Data: data},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 {
+		t.Fatalf("sent %d push notifications, want 2", sent)
+	}
+
+	if len(repo.deleted) != 1 || repo.deleted[0] != "old-tablet" {
+		t.Fatalf("deleted tokens %v, want [old-tablet]", repo.deleted)
+	}
+
+	tokens := map[string]bool{}
+	for _, msg := range provider.Messages() {
+		tokens[msg.Token] = true
+	}
+	if !tokens["phone"] || !tokens["laptop"] || tokens["old-tablet"] {
+		t.Fatalf("pushed to %v", tokens)
+	}
+
+	// the pruned device is not tried again
+	provider.Reset()
+	if sent, err := s.Push([]db.Notification{{ID: uuid.New(), UserID: citizen, Type: string(pkg.NotificationStatusChanged), Data: data}}); err != nil || sent != 1 {
+		t.Fatalf("second push sent %d, %v", sent, err)
+	}
+	if len(repo.deleted) != 1 {
+		t.Fatalf("deleted tokens %v after the second push", repo.deleted)
+	}
+}
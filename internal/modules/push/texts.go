@@ -0,0 +1,93 @@
+package push
+
+import (
+	"encoding/json"
+	"fmt"
+	"hubku/lapor_warga_be_v2/pkg"
+	"strings"
+)
+
+// defaultLocale is the language of devices registered without a known one.
+const defaultLocale = "id"
+
+type text struct {
+	Title string
+	Body  string
+}
+
+// texts are the push notification texts per locale and notification type.
+// The body of status_changed takes the new status.
+var texts = map[string]map[pkg.NotificationType]text{
+	"id": {
+		pkg.NotificationStatusChanged:   {"Status laporan berubah", "Laporan Anda kini %s."},
+		pkg.NotificationCommentReply:    {"Balasan baru", "Seseorang membalas komentar Anda."},
+		pkg.NotificationReportMerged:    {"Laporan digabungkan", "Laporan Anda digabungkan dengan laporan serupa."},
+		pkg.NotificationReportAssigned:  {"Tugas baru", "Sebuah laporan ditugaskan kepada Anda."},
+		pkg.NotificationReportEscalated: {"Laporan dieskalasi", "Sebuah laporan melewati batas waktu SLA dan dieskalasi kepada Anda."},
+	},
+	"en": {
+		pkg.NotificationStatusChanged:   {"Report status changed", "Your report is now %s."},
+		pkg.NotificationCommentReply:    {"New reply", "Someone replied to your comment."},
+		pkg.NotificationReportMerged:    {"Report merged", "Your report was merged into a similar report."},
+		pkg.NotificationReportAssigned:  {"New assignment", "A report was assigned to you."},
+		pkg.NotificationReportEscalated: {"Report escalated", "A report missed its SLA deadline and was escalated to you."},
+	},
+}
+
+var fallbackTexts = map[string]text{
+	"id": {"Lapor Warga", "Anda memiliki notifikasi baru."},
+	"en": {"Lapor Warga", "You have a new notification."},
+}
+
+var statusLabels = map[string]map[pkg.ReportStatus]string{
+	"id": {
+		pkg.ReportUnderReview: "sedang ditinjau",
+		pkg.ReportOpen:        "sedang ditangani",
+		pkg.ReportResolved:    "selesai",
+		pkg.ReportHidden:      "disembunyikan",
+	},
+	"en": {
+		pkg.ReportUnderReview: "under review",
+		pkg.ReportOpen:        "open",
+		pkg.ReportResolved:    "resolved",
+		pkg.ReportHidden:      "hidden",
+	},
+}
+
+// normalizeLocale returns the supported language of locale ("en-US" is
+// "en"), or defaultLocale.
+func normalizeLocale(locale string) string {
+	language := strings.ToLower(locale)
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+
+	if _, ok := texts[language]; ok {
+		return language
+	}
+	return defaultLocale
+}
+
+// render returns the title and body of a notification in locale.
+func render(locale string, notificationType string, data json.RawMessage) (string, string) {
+	t, ok := texts[locale][pkg.NotificationType(notificationType)]
+	if !ok {
+		t = fallbackTexts[locale]
+	}
+
+	if pkg.NotificationType(notificationType) != pkg.NotificationStatusChanged {
+		return t.Title, t.Body
+	}
+
+	var payload struct {
+		NewStatus string `json:"new_status"`
+	}
+	json.Unmarshal(data, &payload)
+
+	status, ok := statusLabels[locale][pkg.ReportStatus(payload.NewStatus)]
+	if !ok {
+		status = payload.NewStatus
+	}
+
+	return t.Title, fmt.Sprintf(t.Body, status)
+}
@@ -0,0 +1,96 @@
+package realtime
+
+import (
+	"hubku/lapor_warga_be_v2/pkg"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// subscriptionBuffer is the number of events a subscriber can fall behind
+// before it is dropped.
+const subscriptionBuffer = 64
+
+// Event is a change to a report that dashboards show as it happens.
+type Event struct {
+	Type     pkg.RealtimeEventType  `json:"type"`
+	ReportID uuid.UUID              `json:"report_id"`
+	AreaID   pgtype.UUID            `json:"area_id"`
+	Data     map[string]interface{} `json:"data"`
+	At       time.Time              `json:"at"`
+}
+
+// Subscription receives the events of a Hub that pass its filter.
+type Subscription struct {
+	events chan Event
+	filter func(Event) bool
+	hub    *Hub
+}
+
+// Events is closed when the subscription is closed, or when the subscriber
+// fell too far behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+// Hub fans events out to the subscribers of this instance.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[*Subscription]struct{})}
+}
+
+// Subscribe returns a subscription to every following event filter accepts.
+func (h *Hub) Subscribe(filter func(Event) bool) *Subscription {
+	sub := &Subscription{
+		events: make(chan Event, subscriptionBuffer),
+		filter: filter,
+		hub:    h,
+	}
+
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub
+}
+
+// Publish hands event to the subscribers that accept it without waiting for
+// them. A subscriber whose buffer is full is dropped, so one slow client
+// cannot hold up the others; it is expected to reconnect and reload.
+func (h *Hub) Publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			delete(h.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.events)
+	}
+}
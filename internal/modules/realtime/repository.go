@@ -0,0 +1,57 @@
+package realtime
+
+import (
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RealtimeRepository interface {
+	Notify(channel, payload string) error
+	Listen(ctx context.Context, channel string, fn func(payload string)) error
+}
+
+type repository struct {
+	pool *pgxpool.Pool
+	db   *db.Queries
+}
+
+func NewRealtimeRepository(pool *pgxpool.Pool) RealtimeRepository {
+	return &repository{pool: pool, db: db.New(pool)}
+}
+
+func (r *repository) Notify(channel, payload string) error {
+	return r.db.NotifyRealtimeEvent(context.Background(), db.NotifyRealtimeEventParams{
+		Channel: channel,
+		Payload: payload,
+	})
+}
+
+// Listen holds a connection of the pool listening on channel and calls fn
+// with every notification, until ctx is done or the connection fails. The
+// connection is closed afterwards instead of going back to the pool, so no
+// pooled connection is left listening.
+func (r *repository) Listen(ctx context.Context, channel string, fn func(payload string)) error {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		conn.Conn().Close(context.Background())
+		conn.Release()
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		fn(notification.Payload)
+	}
+}
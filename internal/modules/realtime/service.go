@@ -0,0 +1,143 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	userareas "hubku/lapor_warga_be_v2/internal/modules/user_areas"
+	"hubku/lapor_warga_be_v2/pkg"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+const (
+	// maxNotifyPayload is the largest payload Postgres NOTIFY accepts.
+	maxNotifyPayload = 8000
+	// relayReconnectDelay is the wait before listening again after the
+	// listening connection failed.
+	relayReconnectDelay = 5 * time.Second
+)
+
+// envelope is an event relayed between instances.
+type envelope struct {
+	Origin uuid.UUID `json:"origin"`
+	Event  Event     `json:"event"`
+}
+
+type RealtimeService interface {
+	Publish(event Event)
+	Subscribe(currentUserID uuid.UUID, role string) (*Subscription, error)
+}
+
+type service struct {
+	repo            RealtimeRepository
+	userAreaService userareas.UserAreasService
+	hub             *Hub
+	// origin tells the events of this instance apart from relayed ones
+	origin  uuid.UUID
+	channel string
+	relay   bool
+}
+
+// NewRealtimeService returns a service that fans report events out to the
+// dashboards connected to this instance. With REALTIME_RELAY, events are
+// also relayed to and received from the other instances through Postgres
+// LISTEN/NOTIFY on REALTIME_CHANNEL.
+func NewRealtimeService(repo RealtimeRepository, userAreaService userareas.UserAreasService) RealtimeService {
+	viper.SetDefault("REALTIME_RELAY", true)
+	viper.SetDefault("REALTIME_CHANNEL", "lapor_warga_events")
+
+	s := &service{
+		repo:            repo,
+		userAreaService: userAreaService,
+		hub:             NewHub(),
+		origin:          uuid.New(),
+		channel:         viper.GetString("REALTIME_CHANNEL"),
+		relay:           viper.GetBool("REALTIME_RELAY"),
+	}
+
+	if s.relay {
+		go s.listen()
+	}
+
+	return s
+}
+
+// Publish delivers event to the subscribers of this instance and relays it
+// to the other instances in the background.
+func (s *service) Publish(event Event) {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	s.hub.Publish(event)
+
+	if !s.relay {
+		return
+	}
+
+	go func() {
+		payload, err := json.Marshal(envelope{Origin: s.origin, Event: event})
+		if err != nil {
+			log.Printf("Failed to encode realtime event: %v", err)
+			return
+		}
+
+		if len(payload) > maxNotifyPayload {
+			log.Printf("Realtime event %s of report %s is too large to relay", event.Type, event.ReportID)
+			return
+		}
+
+		if err := s.repo.Notify(s.channel, string(payload)); err != nil {
+			log.Printf("Failed to relay realtime event: %v", err)
+		}
+	}()
+}
+
+// Subscribe returns a subscription to the events of every report for
+// admins, and of the reports in their jurisdiction, as it is when
+// subscribing, for everyone else.
+func (s *service) Subscribe(currentUserID uuid.UUID, role string) (*Subscription, error) {
+	if role == string(pkg.RoleAdmin) {
+		return s.hub.Subscribe(nil), nil
+	}
+
+	areaIDs, err := s.userAreaService.GetJurisdiction(currentUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	jurisdiction := make(map[uuid.UUID]bool, len(areaIDs))
+	for _, id := range areaIDs {
+		jurisdiction[id] = true
+	}
+
+	return s.hub.Subscribe(func(event Event) bool {
+		return event.AreaID.Valid && jurisdiction[uuid.UUID(event.AreaID.Bytes)]
+	}), nil
+}
+
+func (s *service) listen() {
+	for {
+		err := s.repo.Listen(context.Background(), s.channel, s.receive)
+		log.Printf("Realtime relay stopped, listening again in %s: %v", relayReconnectDelay, err)
+		time.Sleep(relayReconnectDelay)
+	}
+}
+
+func (s *service) receive(payload string) {
+	var relayed envelope
+	if err := json.Unmarshal([]byte(payload), &relayed); err != nil {
+		log.Printf("Failed to decode relayed realtime event: %v", err)
+		return
+	}
+
+	// events of this instance were delivered when they were published
+	if relayed.Origin == s.origin {
+		return
+	}
+
+	s.hub.Publish(relayed.Event)
+}
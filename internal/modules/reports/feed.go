@@ -0,0 +1,47 @@
+package reports
+
+import (
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	FeedHot    = "hot"
+	FeedNew    = "new"
+	FeedTop    = "top"
+	FeedNearby = "nearby"
+)
+
+// feedWindows are the time windows of the top feed, "all" has no limit.
+var feedWindows = map[string]time.Duration{
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+	"year":  365 * 24 * time.Hour,
+	"all":   0,
+}
+
+// FeedItem is a report of the feed. DistanceMeters is only set by the
+// nearby feed.
+type FeedItem struct {
+	db.GetReportFeedRow
+	DistanceMeters *float64 `json:"distance_meters,omitempty"`
+}
+
+type FeedPage struct {
+	Reports    []FeedItem `json:"reports"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// feedCursor is the position of the last report of a page. AsOf is the
+// moment the first page was ranked: later pages rank at the same moment,
+// with the votes and credibility of then, and skip newer reports, so
+// nothing that happens in between shifts them.
+type feedCursor struct {
+	Sort  string    `json:"s"`
+	AsOf  time.Time `json:"t"`
+	Score float64   `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
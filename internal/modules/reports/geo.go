@@ -0,0 +1,142 @@
+package reports
+
+import (
+	"encoding/json"
+	"errors"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultNearbyRadius = 1000.0  // meters
+	maxNearbyRadius     = 50000.0 // meters
+
+	// meters per degree of latitude, and of longitude at the equator
+	metersPerDegree = 111320.0
+)
+
+type boundingBox struct {
+	MinLng, MinLat, MaxLng, MaxLat float64
+}
+
+// radiusBoundingBox returns a box that contains every point within radius
+// meters of lat/lng. It is only a prefilter for the index, so it errs on the
+// large side; the exact distance is checked by the database.
+func radiusBoundingBox(lat, lng, radius float64) boundingBox {
+	// 1% slack covers the difference between the sphere and the ellipsoid
+	radius *= 1.01
+
+	dLat := radius / metersPerDegree
+
+	box := boundingBox{
+		MinLat: math.Max(lat-dLat, -90),
+		MaxLat: math.Min(lat+dLat, 90),
+		MinLng: -180,
+		MaxLng: 180,
+	}
+
+	// longitude degrees shrink towards the poles, measure at the widest latitude of the box
+	widest := math.Max(math.Abs(box.MinLat), math.Abs(box.MaxLat))
+	if cos := math.Cos(widest * math.Pi / 180); cos > 0.01 {
+		dLng := radius / (metersPerDegree * cos)
+		box.MinLng = math.Max(lng-dLng, -180)
+		box.MaxLng = math.Min(lng+dLng, 180)
+	}
+
+	return box
+}
+
+// parseBoundingBox parses "min_lng,min_lat,max_lng,max_lat".
+func parseBoundingBox(value string) (boundingBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return boundingBox{}, errors.New("bbox needs 4 coordinates")
+	}
+
+	var coords [4]float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return boundingBox{}, err
+		}
+		coords[i] = v
+	}
+
+	box := boundingBox{MinLng: coords[0], MinLat: coords[1], MaxLng: coords[2], MaxLat: coords[3]}
+
+	if box.MinLng < -180 || box.MaxLng > 180 || box.MinLat < -90 || box.MaxLat > 90 ||
+		box.MinLng >= box.MaxLng || box.MinLat >= box.MaxLat {
+		return boundingBox{}, errors.New("bbox out of range")
+	}
+
+	return box, nil
+}
+
+const (
+	maxMapZoom = 22
+
+	// size of a map tile in pixels, and how close (in pixels) reports must
+	// be on screen to be merged into one cluster
+	tilePixels        = 256
+	clusterCellPixels = 64
+
+	// circumference of the earth in web mercator meters
+	mercatorCircumference = 40075016.68557849
+
+	// web mercator is undefined past this latitude
+	maxMercatorLat = 85.05112878
+)
+
+const (
+	MapFeatureCluster = "cluster"
+	MapFeaturePoint   = "point"
+)
+
+// MapFeature is either a cluster of reports or a single report on the map.
+type MapFeature struct {
+	Type       string          `json:"type"`
+	Count      int64           `json:"count"`
+	Lat        float64         `json:"lat"`
+	Lng        float64         `json:"lng"`
+	ReportID   *uuid.UUID      `json:"report_id,omitempty"`
+	BBox       []float64       `json:"bbox,omitempty"` // min_lng,min_lat,max_lng,max_lat
+	Categories json.RawMessage `json:"categories"`
+}
+
+func newMapFeature(row db.GetReportClustersRow) MapFeature {
+	feature := MapFeature{
+		Type:       MapFeatureCluster,
+		Count:      row.PointCount,
+		Lat:        row.Lat,
+		Lng:        row.Lng,
+		Categories: row.Categories,
+	}
+
+	if row.PointCount == 1 {
+		reportID := row.ReportID
+		feature.Type = MapFeaturePoint
+		feature.ReportID = &reportID
+		return feature
+	}
+
+	feature.BBox = []float64{row.MinLng, row.MinLat, row.MaxLng, row.MaxLat}
+	return feature
+}
+
+// clusterCellSize returns the side, in web mercator meters, of the grid cells
+// reports are grouped into at zoom.
+func clusterCellSize(zoom int) float64 {
+	metersPerPixel := mercatorCircumference / (tilePixels * math.Pow(2, float64(zoom)))
+	return metersPerPixel * clusterCellPixels
+}
+
+// clampMercator limits the box to the latitudes web mercator can project.
+func (b boundingBox) clampMercator() boundingBox {
+	b.MinLat = math.Max(b.MinLat, -maxMercatorLat)
+	b.MaxLat = math.Min(b.MaxLat, maxMercatorLat)
+	return b
+}
@@ -0,0 +1,357 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/pkg"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReportsRepository interface {
+	CreateReport(arg db.CreateReportParams) (uuid.UUID, error)
+	FindDuplicateReports(arg db.FindDuplicateReportsParams) ([]db.FindDuplicateReportsRow, error)
+	GetReportByID(arg db.GetReportByIDParams) (db.GetReportByIDRow, error)
+	GetReportsByUser(arg db.GetReportsByUserParams) ([]db.GetReportsByUserRow, error)
+	GetReports(arg db.GetReportsParams) ([]db.GetReportsRow, error)
+	GetNearbyReports(arg db.GetNearbyReportsParams) ([]db.GetNearbyReportsRow, error)
+	GetReportClusters(arg db.GetReportClustersParams) ([]db.GetReportClustersRow, error)
+	GetReportFeed(arg db.GetReportFeedParams) ([]db.GetReportFeedRow, error)
+	TransitionStatus(id uuid.UUID, newStatus string, remark pgtype.Text, changedBy uuid.UUID, check func(current db.GetReportForUpdateRow) error) (db.ReportStatusHistory, error)
+	MergeReports(targetID uuid.UUID, sourceIDs []uuid.UUID, mergedBy uuid.UUID, check func(target db.GetReportForUpdateRow, sources []db.GetReportForUpdateRow) error) (db.RecountReportCountersRow, error)
+	AssignReport(id uuid.UUID, assignee pgtype.UUID, assignedBy pgtype.UUID, check func(current db.GetReportForUpdateRow) error) (AssignResult, error)
+	GetAssignedReports(arg db.GetAssignedReportsParams) ([]db.GetAssignedReportsRow, error)
+	CountAssignedReportsByStatus(assignedTo uuid.UUID) ([]db.CountAssignedReportsByStatusRow, error)
+	GetReportStatusHistory(reportID uuid.UUID) ([]db.GetReportStatusHistoryRow, error)
+	FlagLocationMismatch(arg db.FlagReportLocationMismatchParams) error
+}
+
+type repository struct {
+	pool *pgxpool.Pool
+	db   *db.Queries
+}
+
+func NewReportsRepository(pool *pgxpool.Pool) ReportsRepository {
+	return &repository{pool: pool, db: db.New(pool)}
+}
+
+// withTx runs fn inside a single database transaction.
+// The transaction is committed only when fn returns nil.
+func (r *repository) withTx(fn func(q *db.Queries) error) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.db.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *repository) CreateReport(arg db.CreateReportParams) (uuid.UUID, error) {
+	return r.db.CreateReport(context.Background(), arg)
+}
+
+func (r *repository) FindDuplicateReports(arg db.FindDuplicateReportsParams) ([]db.FindDuplicateReportsRow, error) {
+	return r.db.FindDuplicateReports(context.Background(), arg)
+}
+
+func (r *repository) GetReportByID(arg db.GetReportByIDParams) (db.GetReportByIDRow, error) {
+	return r.db.GetReportByID(context.Background(), arg)
+}
+
+func (r *repository) GetReportsByUser(arg db.GetReportsByUserParams) ([]db.GetReportsByUserRow, error) {
+	return r.db.GetReportsByUser(context.Background(), arg)
+}
+
+func (r *repository) GetReports(arg db.GetReportsParams) ([]db.GetReportsRow, error) {
+	return r.db.GetReports(context.Background(), arg)
+}
+
+func (r *repository) GetNearbyReports(arg db.GetNearbyReportsParams) ([]db.GetNearbyReportsRow, error) {
+	return r.db.GetNearbyReports(context.Background(), arg)
+}
+
+func (r *repository) GetReportClusters(arg db.GetReportClustersParams) ([]db.GetReportClustersRow, error) {
+	return r.db.GetReportClusters(context.Background(), arg)
+}
+
+func (r *repository) GetReportFeed(arg db.GetReportFeedParams) ([]db.GetReportFeedRow, error) {
+	return r.db.GetReportFeed(context.Background(), arg)
+}
+
+// TransitionStatus locks the report row and hands its current state to check.
+// If check passes, the new status and its history row are written in the same transaction.
+func (r *repository) TransitionStatus(
+	id uuid.UUID,
+	newStatus string,
+	remark pgtype.Text,
+	changedBy uuid.UUID,
+	check func(current db.GetReportForUpdateRow) error,
+) (db.ReportStatusHistory, error) {
+	var history db.ReportStatusHistory
+
+	err := r.withTx(func(q *db.Queries) error {
+		ctx := context.Background()
+
+		current, err := q.GetReportForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := check(current); err != nil {
+			return err
+		}
+
+		if err := q.UpdateReportStatus(ctx, db.UpdateReportStatusParams{
+			Status: newStatus,
+			ID:     id,
+		}); err != nil {
+			return err
+		}
+
+		history, err = q.CreateReportStatusHistory(ctx, db.CreateReportStatusHistoryParams{
+			ReportID:  id,
+			OldStatus: current.Status,
+			NewStatus: newStatus,
+			Remark:    remark,
+			ChangedBy: pgtype.UUID{
+				Bytes: changedBy,
+				Valid: true,
+			},
+		})
+		return err
+	})
+
+	return history, err
+}
+
+// MergeReports folds the sources into the target. Every report involved is
+// locked, in id order so concurrent merges cannot deadlock, and handed to
+// check before anything moves. Votes, views, comments and attachments move to
+// the target, and the counters of every report involved are recomputed, which
+// leaves the sources at zero. Each source is hidden with a status history row
+// pointing at the target. It all happens in one transaction.
+func (r *repository) MergeReports(
+	targetID uuid.UUID,
+	sourceIDs []uuid.UUID,
+	mergedBy uuid.UUID,
+	check func(target db.GetReportForUpdateRow, sources []db.GetReportForUpdateRow) error,
+) (db.RecountReportCountersRow, error) {
+	var counters db.RecountReportCountersRow
+
+	err := r.withTx(func(q *db.Queries) error {
+		ctx := context.Background()
+
+		ids := append([]uuid.UUID{targetID}, sourceIDs...)
+		sort.Slice(ids, func(i, j int) bool {
+			return bytes.Compare(ids[i][:], ids[j][:]) < 0
+		})
+
+		locked := make(map[uuid.UUID]db.GetReportForUpdateRow, len(ids))
+		for _, id := range ids {
+			report, err := q.GetReportForUpdate(ctx, id)
+			if err != nil {
+				return err
+			}
+			locked[id] = report
+		}
+
+		sources := make([]db.GetReportForUpdateRow, 0, len(sourceIDs))
+		for _, id := range sourceIDs {
+			sources = append(sources, locked[id])
+		}
+
+		if err := check(locked[targetID], sources); err != nil {
+			return err
+		}
+
+		if err := q.MoveReportVotes(ctx, db.MoveReportVotesParams{
+			SourceIds: sourceIDs,
+			TargetID:  targetID,
+		}); err != nil {
+			return err
+		}
+
+		if err := q.MoveReportViews(ctx, db.MoveReportViewsParams{
+			SourceIds: sourceIDs,
+			TargetID:  targetID,
+		}); err != nil {
+			return err
+		}
+
+		if err := q.MoveReportComments(ctx, db.MoveReportCommentsParams{
+			TargetID:  targetID,
+			SourceIds: sourceIDs,
+		}); err != nil {
+			return err
+		}
+
+		if err := q.MoveReportAttachments(ctx, db.MoveReportAttachmentsParams{
+			TargetID:  targetID,
+			SourceIds: sourceIDs,
+		}); err != nil {
+			return err
+		}
+
+		if err := q.RepointMergedReports(ctx, db.RepointMergedReportsParams{
+			TargetID:  targetID,
+			SourceIds: sourceIDs,
+		}); err != nil {
+			return err
+		}
+
+		for _, source := range sources {
+			if err := q.MarkReportMerged(ctx, db.MarkReportMergedParams{
+				MergedIntoID: pgtype.UUID{
+					Bytes: targetID,
+					Valid: true,
+				},
+				ID: source.ID,
+			}); err != nil {
+				return err
+			}
+
+			if _, err := q.CreateReportStatusHistory(ctx, db.CreateReportStatusHistoryParams{
+				ReportID:  source.ID,
+				OldStatus: source.Status,
+				NewStatus: string(pkg.ReportHidden),
+				Remark: pgtype.Text{
+					String: "merged into report " + targetID.String(),
+					Valid:  true,
+				},
+				ChangedBy: pgtype.UUID{
+					Bytes: mergedBy,
+					Valid: true,
+				},
+			}); err != nil {
+				return err
+			}
+
+			if _, err := q.RecountReportCounters(ctx, source.ID); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		counters, err = q.RecountReportCounters(ctx, targetID)
+		return err
+	})
+
+	return counters, err
+}
+
+// AssignReport locks the report and hands it to check, then assigns it to
+// assignee, or round-robin to the official of its area who waited longest for
+// a report when assignee is not set. The assignment is recorded in the status
+// history. ErrNoAssignee is returned when no official covers the area, or
+// when assignee is not one of them.
+func (r *repository) AssignReport(
+	id uuid.UUID,
+	assignee pgtype.UUID,
+	assignedBy pgtype.UUID,
+	check func(current db.GetReportForUpdateRow) error,
+) (AssignResult, error) {
+	var result AssignResult
+
+	err := r.withTx(func(q *db.Queries) error {
+		ctx := context.Background()
+
+		current, err := q.GetReportForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := check(current); err != nil {
+			return err
+		}
+
+		if !current.AreaID.Valid {
+			return ErrNoAssignee
+		}
+
+		// concurrent assignments would otherwise pick the same official
+		if err := q.LockAssignments(ctx); err != nil {
+			return err
+		}
+
+		next, err := q.GetNextAssignee(ctx, db.GetNextAssigneeParams{
+			AreaID: current.AreaID.Bytes,
+			UserID: assignee,
+		})
+		if err != nil {
+			if err.Error() == pkg.ErrNoRows {
+				return ErrNoAssignee
+			}
+			return err
+		}
+
+		result = AssignResult{
+			ReportID:         id,
+			AssignedTo:       next.ID,
+			Username:         next.Username,
+			PreviousAssignee: current.AssignedTo,
+		}
+
+		if current.AssignedTo.Valid && current.AssignedTo.Bytes == next.ID {
+			return nil
+		}
+
+		if err := q.UpdateReportAssignee(ctx, db.UpdateReportAssigneeParams{
+			AssignedTo: pgtype.UUID{
+				Bytes: next.ID,
+				Valid: true,
+			},
+			ID: id,
+		}); err != nil {
+			return err
+		}
+
+		remark := "assigned to " + next.Username
+		if current.AssignedTo.Valid {
+			remark = "reassigned to " + next.Username
+		}
+
+		_, err = q.CreateReportStatusHistory(ctx, db.CreateReportStatusHistoryParams{
+			ReportID:  id,
+			OldStatus: current.Status,
+			NewStatus: current.Status,
+			Remark: pgtype.Text{
+				String: remark,
+				Valid:  true,
+			},
+			ChangedBy: assignedBy,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+func (r *repository) GetAssignedReports(arg db.GetAssignedReportsParams) ([]db.GetAssignedReportsRow, error) {
+	return r.db.GetAssignedReports(context.Background(), arg)
+}
+
+func (r *repository) CountAssignedReportsByStatus(assignedTo uuid.UUID) ([]db.CountAssignedReportsByStatusRow, error) {
+	return r.db.CountAssignedReportsByStatus(context.Background(), pgtype.UUID{
+		Bytes: assignedTo,
+		Valid: true,
+	})
+}
+
+func (r *repository) GetReportStatusHistory(reportID uuid.UUID) ([]db.GetReportStatusHistoryRow, error) {
+	return r.db.GetReportStatusHistory(context.Background(), reportID)
+}
+
+func (r *repository) FlagLocationMismatch(arg db.FlagReportLocationMismatchParams) error {
+	return r.db.FlagReportLocationMismatch(context.Background(), arg)
+}
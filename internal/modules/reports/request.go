@@ -0,0 +1,93 @@
+package reports
+
+import "github.com/google/uuid"
+
+type CreateReportRequest struct {
+	Title       string    `json:"title" form:"title" validate:"required,min=5,max=255"`
+	Description string    `json:"description" form:"description" validate:"required,min=10"`
+	Address     string    `json:"address" form:"address"`
+	Lat         float64   `json:"lat" form:"lat" validate:"required,latitude"`
+	Lng         float64   `json:"lng" form:"lng" validate:"required,longitude"`
+	CategoryID  uuid.UUID `json:"category_id" form:"category_id" validate:"required"`
+
+	// submit even when likely duplicates were found
+	IgnoreDuplicates bool `json:"ignore_duplicates" form:"ignore_duplicates"`
+}
+
+type DuplicateCheckRequest struct {
+	Title       string    `json:"title" validate:"required,max=255"`
+	Description string    `json:"description"`
+	Lat         float64   `json:"lat" validate:"required,latitude"`
+	Lng         float64   `json:"lng" validate:"required,longitude"`
+	CategoryID  uuid.UUID `json:"category_id" validate:"required"`
+}
+
+type ListReportsRequest struct {
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Status     string `json:"status"`
+	CategoryID string `json:"category_id"`
+	AreaID     string `json:"area_id"`
+	From       string `json:"from"` // YYYY-MM-DD
+	To         string `json:"to"`   // YYYY-MM-DD, inclusive
+	SearchTerm string `json:"search_term"`
+
+	// moderators only
+	LocationMismatch string `json:"location_mismatch"` // "true" or "false"
+}
+
+type NearbyReportsRequest struct {
+	Lat        string `json:"lat"`
+	Lng        string `json:"lng"`
+	Radius     string `json:"radius"` // meters
+	BBox       string `json:"bbox"`   // min_lng,min_lat,max_lng,max_lat
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Status     string `json:"status"`
+	CategoryID string `json:"category_id"`
+	From       string `json:"from"` // YYYY-MM-DD
+	To         string `json:"to"`   // YYYY-MM-DD, inclusive
+}
+
+type ClusterReportsRequest struct {
+	BBox       string `json:"bbox"` // min_lng,min_lat,max_lng,max_lat
+	Zoom       int    `json:"zoom"`
+	Status     string `json:"status"`
+	CategoryID string `json:"category_id"`
+	From       string `json:"from"` // YYYY-MM-DD
+	To         string `json:"to"`   // YYYY-MM-DD, inclusive
+}
+
+type FeedRequest struct {
+	Sort       string `json:"sort"`   // hot, new, top or nearby
+	Window     string `json:"window"` // top only: day, week, month, year or all
+	Lat        string `json:"lat"`    // nearby only
+	Lng        string `json:"lng"`    // nearby only
+	Radius     string `json:"radius"` // nearby only, meters
+	CategoryID string `json:"category_id"`
+	Cursor     string `json:"cursor"`
+	Limit      int    `json:"limit"`
+}
+
+type UpdateReportStatusRequest struct {
+	Status string `json:"status" form:"status" validate:"required,oneof=under_review open resolved hidden"`
+	Remark string `json:"remark" form:"remark" validate:"max=1000"`
+}
+
+type MergeReportsRequest struct {
+	SourceIDs []uuid.UUID `json:"source_ids" validate:"required,min=1,max=50"`
+}
+
+type AssignReportRequest struct {
+	// empty assigns round-robin among the officials of the report's area
+	UserID string `json:"user_id" validate:"omitempty,uuid"`
+}
+
+type QueueRequest struct {
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Status     string `json:"status"`
+	CategoryID string `json:"category_id"`
+	From       string `json:"from"` // YYYY-MM-DD
+	To         string `json:"to"`   // YYYY-MM-DD, inclusive
+}
@@ -0,0 +1,1056 @@
+package reports
+
+import (
+	"encoding/json"
+	"errors"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/internal/modules/areas"
+	"hubku/lapor_warga_be_v2/internal/modules/auditlogs"
+	"hubku/lapor_warga_be_v2/internal/modules/categories"
+	"hubku/lapor_warga_be_v2/internal/modules/credibility"
+	"hubku/lapor_warga_be_v2/internal/modules/notifications"
+	"hubku/lapor_warga_be_v2/internal/modules/realtime"
+	userareas "hubku/lapor_warga_be_v2/internal/modules/user_areas"
+	"hubku/lapor_warga_be_v2/pkg"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/spf13/viper"
+)
+
+var (
+	ErrReportNotFound   = errors.New("report not found")
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrInvalidFilter    = errors.New("invalid filter")
+	ErrOutsideArea      = errors.New("location is outside of any active service area")
+	ErrInvalidStatus    = errors.New("invalid status transition")
+	ErrForbiddenStatus  = errors.New("not allowed to perform this status transition")
+	ErrLikelyDuplicate  = errors.New("similar reports already exist nearby")
+	ErrReportMerged     = errors.New("report has been merged into another report")
+	ErrInvalidMerge     = errors.New("invalid merge")
+	ErrNoAssignee       = errors.New("no official covers the report's area")
+)
+
+// MergeResult is the target of a merge with its recomputed counters.
+type MergeResult struct {
+	TargetID      uuid.UUID   `json:"target_id"`
+	SourceIDs     []uuid.UUID `json:"source_ids"`
+	ViewCount     int64       `json:"view_count"`
+	UpvoteCount   int64       `json:"upvote_count"`
+	DownvoteCount int64       `json:"downvote_count"`
+}
+
+// AssignResult is the official a report ended up assigned to.
+type AssignResult struct {
+	ReportID         uuid.UUID   `json:"report_id"`
+	AssignedTo       uuid.UUID   `json:"assigned_to"`
+	Username         string      `json:"username"`
+	PreviousAssignee pgtype.UUID `json:"previous_assignee"`
+}
+
+// WorkQueue is a page of the reports assigned to an official, with the
+// number of assigned reports per status.
+type WorkQueue struct {
+	Reports []db.GetAssignedReportsRow `json:"reports"`
+	Counts  map[string]int64           `json:"counts"`
+}
+
+type ReportsService interface {
+	CreateReport(currentUserID uuid.UUID, req CreateReportRequest) (uuid.UUID, []db.FindDuplicateReportsRow, error)
+	FindDuplicates(req DuplicateCheckRequest) ([]db.FindDuplicateReportsRow, error)
+	GetReportByID(currentUserID uuid.UUID, role string, id uuid.UUID) (db.GetReportByIDRow, error)
+	GetMyReports(currentUserID uuid.UUID, page, limit int) ([]db.GetReportsByUserRow, error)
+	GetReports(currentUserID uuid.UUID, role string, req ListReportsRequest) ([]db.GetReportsRow, error)
+	GetNearbyReports(currentUserID uuid.UUID, role string, req NearbyReportsRequest) ([]db.GetNearbyReportsRow, error)
+	GetReportClusters(currentUserID uuid.UUID, role string, req ClusterReportsRequest) ([]MapFeature, error)
+	GetFeed(currentUserID uuid.UUID, role string, req FeedRequest) (FeedPage, error)
+	TransitionStatus(reportID uuid.UUID, newStatus pkg.ReportStatus, remark string, actor Actor) (db.ReportStatusHistory, error)
+	MergeReports(actor Actor, targetID uuid.UUID, req MergeReportsRequest) (MergeResult, error)
+	AssignReport(actor Actor, reportID uuid.UUID, req AssignReportRequest) (AssignResult, error)
+	GetMyQueue(currentUserID uuid.UUID, req QueueRequest) (WorkQueue, error)
+	GetStatusTimeline(currentUserID uuid.UUID, role string, reportID uuid.UUID) ([]db.GetReportStatusHistoryRow, error)
+	FlagLocationMismatch(reportID uuid.UUID, reason string) error
+}
+
+type service struct {
+	repo            ReportsRepository
+	areaService     areas.AreaService
+	userAreaService userareas.UserAreasService
+	categoryService categories.CategoriesService
+	notifications   notifications.NotificationsService
+	realtime        realtime.RealtimeService
+	credibility     credibility.CredibilityService
+	logService      auditlogs.LogsService
+	hotGravity      float64
+
+	duplicateRadius     float64
+	duplicateWindow     time.Duration
+	duplicateSimilarity float64
+
+	autoAssign bool
+}
+
+func NewReportsService(
+	repo ReportsRepository,
+	areaService areas.AreaService,
+	userAreaService userareas.UserAreasService,
+	categoryService categories.CategoriesService,
+	notificationService notifications.NotificationsService,
+	realtimeService realtime.RealtimeService,
+	credibilityService credibility.CredibilityService,
+	logService auditlogs.LogsService,
+) ReportsService {
+	// how fast hot reports sink with age, higher sinks faster
+	viper.SetDefault("FEED_HOT_GRAVITY", 1.8)
+	// a new report is a likely duplicate of an open or under-review report of
+	// the same category within this distance (meters) and age, with a similar
+	// title or description (pg_trgm similarity, 0-1)
+	viper.SetDefault("DUPLICATE_RADIUS", 50)
+	viper.SetDefault("DUPLICATE_WINDOW", "168h")
+	viper.SetDefault("DUPLICATE_MIN_SIMILARITY", 0.3)
+	// new reports go round-robin to the officials of their area
+	viper.SetDefault("AUTO_ASSIGN_REPORTS", true)
+
+	return &service{
+		repo:            repo,
+		areaService:     areaService,
+		userAreaService: userAreaService,
+		categoryService: categoryService,
+		notifications:   notificationService,
+		realtime:        realtimeService,
+		credibility:     credibilityService,
+		logService:      logService,
+		hotGravity:      viper.GetFloat64("FEED_HOT_GRAVITY"),
+
+		duplicateRadius:     viper.GetFloat64("DUPLICATE_RADIUS"),
+		duplicateWindow:     viper.GetDuration("DUPLICATE_WINDOW"),
+		duplicateSimilarity: viper.GetFloat64("DUPLICATE_MIN_SIMILARITY"),
+
+		autoAssign: viper.GetBool("AUTO_ASSIGN_REPORTS"),
+	}
+}
+
+// CreateReport submits a new report. Unless req.IgnoreDuplicates is set, it
+// refuses with ErrLikelyDuplicate and returns the similar reports when there
+// are any, so the user can upvote one of those instead.
+func (s *service) CreateReport(currentUserID uuid.UUID, req CreateReportRequest) (uuid.UUID, []db.FindDuplicateReportsRow, error) {
+	// only active categories can receive new reports
+	category, err := s.categoryService.GetCategoryById(req.CategoryID)
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return uuid.Nil, nil, ErrCategoryNotFound
+		}
+		return uuid.Nil, nil, err
+	}
+	if !category.IsActive.Bool {
+		return uuid.Nil, nil, ErrCategoryNotFound
+	}
+
+	// reports must fall inside one of our active areas
+	areaID, err := s.areaService.FindAreaByPoint(req.Lat, req.Lng)
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return uuid.Nil, nil, ErrOutsideArea
+		}
+		return uuid.Nil, nil, err
+	}
+
+	if !req.IgnoreDuplicates {
+		duplicates, err := s.FindDuplicates(DuplicateCheckRequest{
+			Title:       req.Title,
+			Description: req.Description,
+			Lat:         req.Lat,
+			Lng:         req.Lng,
+			CategoryID:  req.CategoryID,
+		})
+		if err != nil {
+			return uuid.Nil, nil, err
+		}
+		if len(duplicates) > 0 {
+			return uuid.Nil, duplicates, ErrLikelyDuplicate
+		}
+	}
+
+	result, err := s.repo.CreateReport(db.CreateReportParams{
+		Title:       req.Title,
+		Description: req.Description,
+		Address: pgtype.Text{
+			String: req.Address,
+			Valid:  req.Address != "",
+		},
+		Lng: req.Lng,
+		Lat: req.Lat,
+		AreaID: pgtype.UUID{
+			Bytes: areaID,
+			Valid: true,
+		},
+		CategoryID: req.CategoryID,
+		UserID:     currentUserID,
+	})
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	// log create report
+	go func() {
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityReports),
+			Action:      string(pkg.LogTypeCreate),
+			EntityID:    result,
+			PerformedBy: currentUserID,
+		})
+	}()
+
+	s.realtime.Publish(realtime.Event{
+		Type:     pkg.EventReportCreated,
+		ReportID: result,
+		AreaID:   pgtype.UUID{Bytes: areaID, Valid: true},
+		Data: map[string]interface{}{
+			"title":       req.Title,
+			"status":      pkg.ReportUnderReview,
+			"category_id": req.CategoryID,
+		},
+	})
+
+	if s.autoAssign {
+		go func() {
+			assigned, err := s.repo.AssignReport(result, pgtype.UUID{}, pgtype.UUID{}, func(db.GetReportForUpdateRow) error {
+				return nil
+			})
+			if err != nil {
+				if !errors.Is(err, ErrNoAssignee) {
+					log.Printf("failed to assign report %s: %v", result, err)
+				}
+				return
+			}
+			s.notifyAssigned(assigned, uuid.Nil)
+		}()
+	}
+
+	return result, nil, nil
+}
+
+// FindDuplicates returns the reports a new report would likely duplicate,
+// most similar first.
+func (s *service) FindDuplicates(req DuplicateCheckRequest) ([]db.FindDuplicateReportsRow, error) {
+	box := radiusBoundingBox(req.Lat, req.Lng, s.duplicateRadius)
+
+	return s.repo.FindDuplicateReports(db.FindDuplicateReportsParams{
+		Lng:           req.Lng,
+		Lat:           req.Lat,
+		Title:         req.Title,
+		Description:   req.Description,
+		CategoryID:    req.CategoryID,
+		CreatedFrom:   time.Now().Add(-s.duplicateWindow),
+		MinLng:        box.MinLng,
+		MinLat:        box.MinLat,
+		MaxLng:        box.MaxLng,
+		MaxLat:        box.MaxLat,
+		RadiusMeters:  s.duplicateRadius,
+		MinSimilarity: s.duplicateSimilarity,
+		LimitCount:    5,
+	})
+}
+
+func (s *service) GetReportByID(currentUserID uuid.UUID, role string, id uuid.UUID) (db.GetReportByIDRow, error) {
+	report, err := s.repo.GetReportByID(db.GetReportByIDParams{
+		UserID: currentUserID,
+		ID:     id,
+	})
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return db.GetReportByIDRow{}, ErrReportNotFound
+		}
+		return db.GetReportByIDRow{}, err
+	}
+
+	// citizens can only see public reports, or their own
+	if role == string(pkg.RoleCitizen) && report.UserID != currentUserID && !isPublicStatus(report.Status) {
+		return db.GetReportByIDRow{}, ErrReportNotFound
+	}
+
+	// officials only see reports in their jurisdiction
+	areaIDs, err := s.jurisdiction(currentUserID, role)
+	if err != nil {
+		return db.GetReportByIDRow{}, err
+	}
+	if !inJurisdiction(areaIDs, report.AreaID) {
+		return db.GetReportByIDRow{}, ErrReportNotFound
+	}
+
+	// the location mismatch signal is for moderators only
+	if role == string(pkg.RoleCitizen) {
+		report.LocationMismatch = pgtype.Bool{}
+		report.LocationMismatchReason = pgtype.Text{}
+	}
+
+	return report, nil
+}
+
+func (s *service) GetMyReports(currentUserID uuid.UUID, page, limit int) ([]db.GetReportsByUserRow, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if limit <= 0 {
+		limit = 20
+	}
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	return s.repo.GetReportsByUser(db.GetReportsByUserParams{
+		UserID:      currentUserID,
+		OffsetCount: int32((page - 1) * limit),
+		LimitCount:  int32(limit),
+	})
+}
+
+func (s *service) GetReports(currentUserID uuid.UUID, role string, req ListReportsRequest) ([]db.GetReportsRow, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	filters, err := parseReportFilters(req.Status, req.CategoryID, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	areaIDs, err := s.jurisdiction(currentUserID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.GetReportsParams{
+		PublicOnly:  role == string(pkg.RoleCitizen),
+		AreaIds:     areaIDs,
+		Status:      filters.Status,
+		CategoryID:  filters.CategoryID,
+		CreatedFrom: filters.CreatedFrom,
+		CreatedTo:   filters.CreatedTo,
+		SearchTerm:  req.SearchTerm,
+		OffsetCount: int32((req.Page - 1) * req.Limit),
+		LimitCount:  int32(req.Limit),
+	}
+
+	if req.AreaID != "" {
+		areaID, err := uuid.Parse(req.AreaID)
+		if err != nil {
+			return nil, ErrInvalidFilter
+		}
+		arg.AreaID = pgtype.UUID{Bytes: areaID, Valid: true}
+	}
+
+	if req.LocationMismatch != "" && role != string(pkg.RoleCitizen) {
+		mismatch, err := strconv.ParseBool(req.LocationMismatch)
+		if err != nil {
+			return nil, ErrInvalidFilter
+		}
+		arg.LocationMismatch = pgtype.Bool{Bool: mismatch, Valid: true}
+	}
+
+	result, err := s.repo.GetReports(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	// the location mismatch signal is for moderators only
+	if role == string(pkg.RoleCitizen) {
+		for i := range result {
+			result[i].LocationMismatch = pgtype.Bool{}
+		}
+	}
+
+	return result, nil
+}
+
+// GetNearbyReports returns reports around the caller, closest first. The
+// search area is either a radius in meters around lat/lng or a bounding box;
+// distances are always measured from lat/lng.
+func (s *service) GetNearbyReports(currentUserID uuid.UUID, role string, req NearbyReportsRequest) ([]db.GetNearbyReportsRow, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	lat, err := strconv.ParseFloat(req.Lat, 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, ErrInvalidFilter
+	}
+
+	lng, err := strconv.ParseFloat(req.Lng, 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return nil, ErrInvalidFilter
+	}
+
+	filters, err := parseReportFilters(req.Status, req.CategoryID, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	areaIDs, err := s.jurisdiction(currentUserID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.GetNearbyReportsParams{
+		Lng:         lng,
+		Lat:         lat,
+		PublicOnly:  role == string(pkg.RoleCitizen),
+		AreaIds:     areaIDs,
+		Status:      filters.Status,
+		CategoryID:  filters.CategoryID,
+		CreatedFrom: filters.CreatedFrom,
+		CreatedTo:   filters.CreatedTo,
+		OffsetCount: int32((req.Page - 1) * req.Limit),
+		LimitCount:  int32(req.Limit),
+	}
+
+	var box boundingBox
+
+	switch {
+	case req.BBox != "":
+		box, err = parseBoundingBox(req.BBox)
+		if err != nil {
+			return nil, ErrInvalidFilter
+		}
+	default:
+		radius := defaultNearbyRadius
+		if req.Radius != "" {
+			radius, err = strconv.ParseFloat(req.Radius, 64)
+			if err != nil || radius <= 0 || radius > maxNearbyRadius {
+				return nil, ErrInvalidFilter
+			}
+		}
+		box = radiusBoundingBox(lat, lng, radius)
+		arg.RadiusMeters = pgtype.Float8{Float64: radius, Valid: true}
+	}
+
+	arg.MinLng, arg.MinLat, arg.MaxLng, arg.MaxLat = box.MinLng, box.MinLat, box.MaxLng, box.MaxLat
+
+	return s.repo.GetNearbyReports(arg)
+}
+
+// GetReportClusters groups the reports inside a bounding box for a map at the
+// given zoom level. Reports closer than about clusterCellPixels on screen end
+// up in the same cluster; a cell holding a single report comes back as a point.
+func (s *service) GetReportClusters(currentUserID uuid.UUID, role string, req ClusterReportsRequest) ([]MapFeature, error) {
+	if req.Zoom < 0 || req.Zoom > maxMapZoom {
+		return nil, ErrInvalidFilter
+	}
+
+	box, err := parseBoundingBox(req.BBox)
+	if err != nil {
+		return nil, ErrInvalidFilter
+	}
+	box = box.clampMercator()
+
+	filters, err := parseReportFilters(req.Status, req.CategoryID, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	areaIDs, err := s.jurisdiction(currentUserID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.GetReportClusters(db.GetReportClustersParams{
+		CellSize:    clusterCellSize(req.Zoom),
+		MinLng:      box.MinLng,
+		MinLat:      box.MinLat,
+		MaxLng:      box.MaxLng,
+		MaxLat:      box.MaxLat,
+		PublicOnly:  role == string(pkg.RoleCitizen),
+		AreaIds:     areaIDs,
+		Status:      filters.Status,
+		CategoryID:  filters.CategoryID,
+		CreatedFrom: filters.CreatedFrom,
+		CreatedTo:   filters.CreatedTo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	features := make([]MapFeature, 0, len(rows))
+	for _, row := range rows {
+		features = append(features, newMapFeature(row))
+	}
+
+	return features, nil
+}
+
+// GetFeed returns a page of the report feed. The first page fixes the moment
+// reports are ranked at; the returned cursor carries it, together with the
+// score of the last report, so following pages neither repeat nor skip
+// reports when new ones come in, scores decay or votes are cast.
+func (s *service) GetFeed(currentUserID uuid.UUID, role string, req FeedRequest) (FeedPage, error) {
+	if req.Sort == "" {
+		req.Sort = FeedHot
+	}
+
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	areaIDs, err := s.jurisdiction(currentUserID, role)
+	if err != nil {
+		return FeedPage{}, err
+	}
+
+	arg := db.GetReportFeedParams{
+		SortMode:   req.Sort,
+		AsOf:       time.Now(),
+		Gravity:    s.hotGravity,
+		PublicOnly: role == string(pkg.RoleCitizen),
+		AreaIds:    areaIDs,
+		// fetch one extra row to know whether there is a next page
+		LimitCount: int32(req.Limit + 1),
+	}
+
+	if req.Cursor != "" {
+		var position feedCursor
+		if err := pkg.DecodeCursor(req.Cursor, &position); err != nil {
+			return FeedPage{}, err
+		}
+		if position.Sort != req.Sort {
+			return FeedPage{}, pkg.ErrInvalidCursor
+		}
+		arg.AsOf = position.AsOf
+		arg.CursorScore = pgtype.Float8{Float64: position.Score, Valid: true}
+		arg.CursorID = pgtype.UUID{Bytes: position.ID, Valid: true}
+	}
+
+	if req.CategoryID != "" {
+		categoryID, err := uuid.Parse(req.CategoryID)
+		if err != nil {
+			return FeedPage{}, ErrInvalidFilter
+		}
+		arg.CategoryID = pgtype.UUID{Bytes: categoryID, Valid: true}
+	}
+
+	switch req.Sort {
+	case FeedHot, FeedNew:
+	case FeedTop:
+		if req.Window == "" {
+			req.Window = "week"
+		}
+		window, ok := feedWindows[req.Window]
+		if !ok {
+			return FeedPage{}, ErrInvalidFilter
+		}
+		if window > 0 {
+			arg.CreatedFrom = pgtype.Timestamptz{Time: arg.AsOf.Add(-window), Valid: true}
+		}
+	case FeedNearby:
+		lat, err := strconv.ParseFloat(req.Lat, 64)
+		if err != nil || lat < -90 || lat > 90 {
+			return FeedPage{}, ErrInvalidFilter
+		}
+
+		lng, err := strconv.ParseFloat(req.Lng, 64)
+		if err != nil || lng < -180 || lng > 180 {
+			return FeedPage{}, ErrInvalidFilter
+		}
+
+		radius := defaultNearbyRadius
+		if req.Radius != "" {
+			radius, err = strconv.ParseFloat(req.Radius, 64)
+			if err != nil || radius <= 0 || radius > maxNearbyRadius {
+				return FeedPage{}, ErrInvalidFilter
+			}
+		}
+
+		box := radiusBoundingBox(lat, lng, radius)
+
+		arg.Lat = pgtype.Float8{Float64: lat, Valid: true}
+		arg.Lng = pgtype.Float8{Float64: lng, Valid: true}
+		arg.RadiusMeters = pgtype.Float8{Float64: radius, Valid: true}
+		arg.MinLng = pgtype.Float8{Float64: box.MinLng, Valid: true}
+		arg.MinLat = pgtype.Float8{Float64: box.MinLat, Valid: true}
+		arg.MaxLng = pgtype.Float8{Float64: box.MaxLng, Valid: true}
+		arg.MaxLat = pgtype.Float8{Float64: box.MaxLat, Valid: true}
+	default:
+		return FeedPage{}, ErrInvalidFilter
+	}
+
+	rows, err := s.repo.GetReportFeed(arg)
+	if err != nil {
+		return FeedPage{}, err
+	}
+
+	page := FeedPage{Reports: make([]FeedItem, 0, len(rows))}
+
+	if len(rows) > req.Limit {
+		rows = rows[:req.Limit]
+		last := rows[len(rows)-1]
+		page.NextCursor = pkg.EncodeCursor(feedCursor{
+			Sort:  req.Sort,
+			AsOf:  arg.AsOf,
+			Score: last.RankScore,
+			ID:    last.ID,
+		})
+	}
+
+	for _, row := range rows {
+		item := FeedItem{GetReportFeedRow: row}
+		if req.Sort == FeedNearby {
+			// the nearby feed ranks by negated distance
+			distance := -row.RankScore
+			item.DistanceMeters = &distance
+		}
+		page.Reports = append(page.Reports, item)
+	}
+
+	return page, nil
+}
+
+func (s *service) TransitionStatus(reportID uuid.UUID, newStatus pkg.ReportStatus, remark string, actor Actor) (db.ReportStatusHistory, error) {
+	if !isValidStatus(string(newStatus)) {
+		return db.ReportStatusHistory{}, ErrInvalidStatus
+	}
+
+	areaIDs, err := s.jurisdiction(actor.ID, string(actor.Role))
+	if err != nil {
+		return db.ReportStatusHistory{}, err
+	}
+
+	var (
+		oldStatus string
+		ownerID   uuid.UUID
+		areaID    pgtype.UUID
+	)
+
+	history, err := s.repo.TransitionStatus(
+		reportID,
+		string(newStatus),
+		pgtype.Text{
+			String: remark,
+			Valid:  remark != "",
+		},
+		actor.ID,
+		func(current db.GetReportForUpdateRow) error {
+			oldStatus = current.Status
+			ownerID = current.UserID
+			areaID = current.AreaID
+
+			// merged reports stay hidden behind their target
+			if current.MergedIntoID.Valid {
+				return ErrReportMerged
+			}
+
+			exists, allowed := canTransition(pkg.ReportStatus(current.Status), newStatus, actor.Role)
+			if !exists {
+				return ErrInvalidStatus
+			}
+			if !allowed {
+				return ErrForbiddenStatus
+			}
+
+			// citizens can only act on their own reports
+			if actor.Role == pkg.RoleCitizen && current.UserID != actor.ID {
+				return ErrForbiddenStatus
+			}
+
+			// officials only act on reports in their jurisdiction
+			if !inJurisdiction(areaIDs, current.AreaID) {
+				return ErrReportNotFound
+			}
+
+			return nil
+		},
+	)
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return db.ReportStatusHistory{}, ErrReportNotFound
+		}
+		return db.ReportStatusHistory{}, err
+	}
+
+	// log status transition
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"old_status": oldStatus,
+			"new_status": newStatus,
+			"remark":     remark,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityReports),
+			Action:      string(pkg.LogTypeUpdate),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    reportID,
+			PerformedBy: actor.ID,
+		})
+	}()
+
+	s.realtime.Publish(realtime.Event{
+		Type:     pkg.EventReportStatusChanged,
+		ReportID: reportID,
+		AreaID:   areaID,
+		Data: map[string]interface{}{
+			"old_status": oldStatus,
+			"new_status": newStatus,
+		},
+	})
+
+	// tell the reporter
+	go func() {
+		if _, err := s.notifications.Notify(notifications.Event{
+			Type:       pkg.NotificationStatusChanged,
+			Recipients: []uuid.UUID{ownerID},
+			ReportID:   reportID,
+			ActorID:    actor.ID,
+			Data: map[string]interface{}{
+				"old_status": oldStatus,
+				"new_status": newStatus,
+				"remark":     remark,
+			},
+		}); err != nil {
+			log.Printf("failed to notify status change of report %s: %v", reportID, err)
+		}
+	}()
+
+	// score the reporter on the outcome of the report
+	var scored pkg.CredibilityEvent
+	switch newStatus {
+	case pkg.ReportResolved:
+		scored = pkg.CredibilityReportResolved
+	case pkg.ReportHidden:
+		scored = pkg.CredibilityReportHidden
+	}
+	if scored != "" {
+		go func() {
+			if err := s.credibility.Record(credibility.Event{
+				Type:     scored,
+				UserID:   ownerID,
+				ReportID: reportID,
+				ActorID:  actor.ID,
+			}); err != nil {
+				log.Printf("failed to score status change of report %s: %v", reportID, err)
+			}
+		}()
+	}
+
+	return history, nil
+}
+
+// MergeReports folds duplicate reports into the target report. The sources
+// must not be merged already, the target must be neither merged nor hidden.
+func (s *service) MergeReports(actor Actor, targetID uuid.UUID, req MergeReportsRequest) (MergeResult, error) {
+	sourceIDs := make([]uuid.UUID, 0, len(req.SourceIDs))
+	seen := make(map[uuid.UUID]bool, len(req.SourceIDs))
+	for _, id := range req.SourceIDs {
+		if id == targetID {
+			return MergeResult{}, ErrInvalidMerge
+		}
+		if !seen[id] {
+			seen[id] = true
+			sourceIDs = append(sourceIDs, id)
+		}
+	}
+
+	areaIDs, err := s.jurisdiction(actor.ID, string(actor.Role))
+	if err != nil {
+		return MergeResult{}, err
+	}
+
+	var ownerIDs []uuid.UUID
+
+	counters, err := s.repo.MergeReports(targetID, sourceIDs, actor.ID, func(target db.GetReportForUpdateRow, sources []db.GetReportForUpdateRow) error {
+		// officials only merge reports in their jurisdiction
+		if !inJurisdiction(areaIDs, target.AreaID) {
+			return ErrReportNotFound
+		}
+		for _, source := range sources {
+			if !inJurisdiction(areaIDs, source.AreaID) {
+				return ErrReportNotFound
+			}
+		}
+
+		if target.MergedIntoID.Valid {
+			return ErrReportMerged
+		}
+		if target.Status == string(pkg.ReportHidden) {
+			return ErrInvalidMerge
+		}
+
+		ownerIDs = ownerIDs[:0]
+		for _, source := range sources {
+			if source.MergedIntoID.Valid {
+				return ErrReportMerged
+			}
+			ownerIDs = append(ownerIDs, source.UserID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return MergeResult{}, ErrReportNotFound
+		}
+		return MergeResult{}, err
+	}
+
+	result := MergeResult{
+		TargetID:      targetID,
+		SourceIDs:     sourceIDs,
+		ViewCount:     counters.ViewCount.Int64,
+		UpvoteCount:   counters.UpvoteCount.Int64,
+		DownvoteCount: counters.DownvoteCount.Int64,
+	}
+
+	// log merge
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"source_ids": sourceIDs,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityReports),
+			Action:      string(pkg.LogTypeMerge),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    targetID,
+			PerformedBy: actor.ID,
+		})
+	}()
+
+	// tell the reporters of the merged reports where their report went
+	go func() {
+		if _, err := s.notifications.Notify(notifications.Event{
+			Type:       pkg.NotificationReportMerged,
+			Recipients: ownerIDs,
+			ReportID:   targetID,
+			ActorID:    actor.ID,
+			Data: map[string]interface{}{
+				"source_ids": sourceIDs,
+			},
+		}); err != nil {
+			log.Printf("failed to notify merge into report %s: %v", targetID, err)
+		}
+	}()
+
+	return result, nil
+}
+
+// AssignReport assigns a report to the official in req, who must cover the
+// report's area, or round-robin among those officials when req names nobody.
+func (s *service) AssignReport(actor Actor, reportID uuid.UUID, req AssignReportRequest) (AssignResult, error) {
+	var assignee pgtype.UUID
+	if req.UserID != "" {
+		userID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			return AssignResult{}, ErrNoAssignee
+		}
+		assignee = pgtype.UUID{Bytes: userID, Valid: true}
+	}
+
+	result, err := s.repo.AssignReport(reportID, assignee, pgtype.UUID{Bytes: actor.ID, Valid: true}, func(current db.GetReportForUpdateRow) error {
+		if current.MergedIntoID.Valid {
+			return ErrReportMerged
+		}
+		return nil
+	})
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return AssignResult{}, ErrReportNotFound
+		}
+		return AssignResult{}, err
+	}
+
+	// log assignment
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"assigned_to":       result.AssignedTo,
+			"previous_assignee": result.PreviousAssignee,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityReports),
+			Action:      string(pkg.LogTypeAssign),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    reportID,
+			PerformedBy: actor.ID,
+		})
+	}()
+
+	go s.notifyAssigned(result, actor.ID)
+
+	return result, nil
+}
+
+// notifyAssigned tells the official a report was assigned to. actorID is
+// uuid.Nil for automatic assignments.
+func (s *service) notifyAssigned(result AssignResult, actorID uuid.UUID) {
+	if _, err := s.notifications.Notify(notifications.Event{
+		Type:       pkg.NotificationReportAssigned,
+		Recipients: []uuid.UUID{result.AssignedTo},
+		ReportID:   result.ReportID,
+		ActorID:    actorID,
+	}); err != nil {
+		log.Printf("failed to notify assignment of report %s: %v", result.ReportID, err)
+	}
+}
+
+// GetMyQueue returns the reports assigned to the current user, most recently
+// assigned first, with counts per status over all of them.
+func (s *service) GetMyQueue(currentUserID uuid.UUID, req QueueRequest) (WorkQueue, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	filters, err := parseReportFilters(req.Status, req.CategoryID, req.From, req.To)
+	if err != nil {
+		return WorkQueue{}, err
+	}
+
+	reports, err := s.repo.GetAssignedReports(db.GetAssignedReportsParams{
+		AssignedTo:  pgtype.UUID{Bytes: currentUserID, Valid: true},
+		Status:      filters.Status,
+		CategoryID:  filters.CategoryID,
+		CreatedFrom: filters.CreatedFrom,
+		CreatedTo:   filters.CreatedTo,
+		OffsetCount: int32((req.Page - 1) * req.Limit),
+		LimitCount:  int32(req.Limit),
+	})
+	if err != nil {
+		return WorkQueue{}, err
+	}
+
+	rows, err := s.repo.CountAssignedReportsByStatus(currentUserID)
+	if err != nil {
+		return WorkQueue{}, err
+	}
+
+	counts := map[string]int64{
+		string(pkg.ReportUnderReview): 0,
+		string(pkg.ReportOpen):        0,
+		string(pkg.ReportResolved):    0,
+		string(pkg.ReportHidden):      0,
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return WorkQueue{Reports: reports, Counts: counts}, nil
+}
+
+func (s *service) GetStatusTimeline(currentUserID uuid.UUID, role string, reportID uuid.UUID) ([]db.GetReportStatusHistoryRow, error) {
+	// reuse the visibility rules of the report detail
+	if _, err := s.GetReportByID(currentUserID, role, reportID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetReportStatusHistory(reportID)
+}
+
+func (s *service) FlagLocationMismatch(reportID uuid.UUID, reason string) error {
+	return s.repo.FlagLocationMismatch(db.FlagReportLocationMismatchParams{
+		Reason: reason,
+		ID:     reportID,
+	})
+}
+
+// reportFilters are the filters shared by the report listings.
+type reportFilters struct {
+	Status      pgtype.Text
+	CategoryID  pgtype.UUID
+	CreatedFrom pgtype.Timestamptz
+	CreatedTo   pgtype.Timestamptz
+}
+
+func parseReportFilters(status, categoryID, from, to string) (reportFilters, error) {
+	var filters reportFilters
+
+	if status != "" {
+		if !isValidStatus(status) {
+			return reportFilters{}, ErrInvalidFilter
+		}
+		filters.Status = pgtype.Text{String: status, Valid: true}
+	}
+
+	if categoryID != "" {
+		id, err := uuid.Parse(categoryID)
+		if err != nil {
+			return reportFilters{}, ErrInvalidFilter
+		}
+		filters.CategoryID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	if from != "" {
+		t, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			return reportFilters{}, ErrInvalidFilter
+		}
+		filters.CreatedFrom = pgtype.Timestamptz{Time: t, Valid: true}
+	}
+
+	if to != "" {
+		t, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			return reportFilters{}, ErrInvalidFilter
+		}
+		// "to" is inclusive, so compare against the start of the next day
+		filters.CreatedTo = pgtype.Timestamptz{Time: t.AddDate(0, 0, 1), Valid: true}
+	}
+
+	return filters, nil
+}
+
+func isValidStatus(status string) bool {
+	switch pkg.ReportStatus(status) {
+	case pkg.ReportUnderReview, pkg.ReportOpen, pkg.ReportResolved, pkg.ReportHidden:
+		return true
+	}
+	return false
+}
+
+// jurisdiction returns the areas, descendants included, an official is
+// responsible for, or nil when the role is not limited to areas. An official
+// without areas gets an empty list and so sees no reports at all.
+func (s *service) jurisdiction(currentUserID uuid.UUID, role string) ([]uuid.UUID, error) {
+	if role != string(pkg.RoleOfficial) {
+		return nil, nil
+	}
+	return s.userAreaService.GetJurisdiction(currentUserID)
+}
+
+// inJurisdiction reports whether areaID is one of areaIDs. A nil list means
+// no limit.
+func inJurisdiction(areaIDs []uuid.UUID, areaID pgtype.UUID) bool {
+	if areaIDs == nil {
+		return true
+	}
+	if !areaID.Valid {
+		return false
+	}
+	for _, id := range areaIDs {
+		if id == uuid.UUID(areaID.Bytes) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPublicStatus(status string) bool {
+	return status == string(pkg.ReportOpen) || status == string(pkg.ReportResolved)
+}
@@ -0,0 +1,50 @@
+package reports
+
+import (
+	"hubku/lapor_warga_be_v2/pkg"
+
+	"github.com/google/uuid"
+)
+
+// Actor is the user performing an action on a report.
+type Actor struct {
+	ID   uuid.UUID
+	Role pkg.RoleType
+}
+
+// statusTransitions lists every allowed status move and the roles that may perform it.
+// Citizens may only reopen their own resolved reports; ownership is checked by the service.
+var statusTransitions = map[pkg.ReportStatus]map[pkg.ReportStatus][]pkg.RoleType{
+	pkg.ReportUnderReview: {
+		pkg.ReportOpen:   {pkg.RoleOfficial, pkg.RoleAdmin},
+		pkg.ReportHidden: {pkg.RoleOfficial, pkg.RoleAdmin},
+	},
+	pkg.ReportOpen: {
+		pkg.ReportResolved: {pkg.RoleOfficial, pkg.RoleAdmin},
+		pkg.ReportHidden:   {pkg.RoleOfficial, pkg.RoleAdmin},
+	},
+	pkg.ReportResolved: {
+		pkg.ReportOpen: {pkg.RoleCitizen, pkg.RoleOfficial, pkg.RoleAdmin},
+	},
+	pkg.ReportHidden: {
+		pkg.ReportUnderReview: {pkg.RoleAdmin},
+		pkg.ReportOpen:        {pkg.RoleAdmin},
+	},
+}
+
+// canTransition reports whether the move from -> to exists in the transition
+// table, and whether role is allowed to perform it.
+func canTransition(from, to pkg.ReportStatus, role pkg.RoleType) (exists bool, allowed bool) {
+	roles, ok := statusTransitions[from][to]
+	if !ok {
+		return false, false
+	}
+
+	for _, r := range roles {
+		if r == role {
+			return true, true
+		}
+	}
+
+	return true, false
+}
@@ -0,0 +1,111 @@
+package sla
+
+import (
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SLARepository interface {
+	GetSLACandidates(arg db.GetSLACandidatesParams) ([]db.GetSLACandidatesRow, error)
+	SetSLADeadline(arg db.SetSLADeadlineParams) error
+	Escalate(arg db.CreateReportEscalationParams, remark string) (bool, error)
+	GetReportSLA(id uuid.UUID) (db.GetReportSLARow, error)
+	GetEscalations(arg db.GetEscalationsParams) ([]db.GetEscalationsRow, error)
+	GetEscalationRecipients(areaID pgtype.UUID) ([]uuid.UUID, error)
+}
+
+type repository struct {
+	pool *pgxpool.Pool
+	db   *db.Queries
+}
+
+func NewSLARepository(pool *pgxpool.Pool) SLARepository {
+	return &repository{pool: pool, db: db.New(pool)}
+}
+
+// withTx runs fn inside a single database transaction.
+// The transaction is committed only when fn returns nil.
+func (r *repository) withTx(fn func(q *db.Queries) error) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.db.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *repository) GetSLACandidates(arg db.GetSLACandidatesParams) ([]db.GetSLACandidatesRow, error) {
+	return r.db.GetSLACandidates(context.Background(), arg)
+}
+
+func (r *repository) SetSLADeadline(arg db.SetSLADeadlineParams) error {
+	return r.db.SetSLADeadline(context.Background(), arg)
+}
+
+// Escalate records a breach and puts it on the report's timeline. It reports
+// false when the breach was already escalated or the report was resolved or
+// hidden in the meantime.
+func (r *repository) Escalate(arg db.CreateReportEscalationParams, remark string) (bool, error) {
+	escalated := false
+
+	err := r.withTx(func(q *db.Queries) error {
+		ctx := context.Background()
+
+		current, err := q.GetReportForUpdate(ctx, arg.ReportID)
+		if err != nil {
+			return err
+		}
+
+		if current.Status != string(pkg.ReportUnderReview) && current.Status != string(pkg.ReportOpen) {
+			return nil
+		}
+
+		if _, err := q.CreateReportEscalation(ctx, arg); err != nil {
+			if err.Error() == pkg.ErrNoRows {
+				return nil
+			}
+			return err
+		}
+
+		if _, err := q.CreateReportStatusHistory(ctx, db.CreateReportStatusHistoryParams{
+			ReportID:  arg.ReportID,
+			OldStatus: current.Status,
+			NewStatus: current.Status,
+			Remark: pgtype.Text{
+				String: remark,
+				Valid:  true,
+			},
+		}); err != nil {
+			return err
+		}
+
+		escalated = true
+		return nil
+	})
+
+	return escalated, err
+}
+
+func (r *repository) GetReportSLA(id uuid.UUID) (db.GetReportSLARow, error) {
+	return r.db.GetReportSLA(context.Background(), id)
+}
+
+func (r *repository) GetEscalations(arg db.GetEscalationsParams) ([]db.GetEscalationsRow, error) {
+	return r.db.GetEscalations(context.Background(), arg)
+}
+
+func (r *repository) GetEscalationRecipients(areaID pgtype.UUID) ([]uuid.UUID, error) {
+	return r.db.GetEscalationRecipients(context.Background(), areaID)
+}
@@ -0,0 +1,9 @@
+package sla
+
+type EscalationsRequest struct {
+	Page  int    `json:"page"`
+	Limit int    `json:"limit"`
+	Kind  string `json:"kind"` // response or resolution, empty for both
+	// OpenOnly leaves out reports that were resolved or hidden since
+	OpenOnly bool `json:"open_only"`
+}
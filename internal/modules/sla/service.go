@@ -0,0 +1,311 @@
+package sla
+
+import (
+	"errors"
+	"fmt"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/internal/modules/calendar"
+	"hubku/lapor_warga_be_v2/internal/modules/notifications"
+	"hubku/lapor_warga_be_v2/internal/modules/reports"
+	userareas "hubku/lapor_warga_be_v2/internal/modules/user_areas"
+	"hubku/lapor_warga_be_v2/pkg"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/spf13/viper"
+)
+
+var ErrInvalidKind = errors.New("invalid escalation kind")
+
+// Status is how a report stands against one SLA of its category, in the
+// business time of its area.
+type Status struct {
+	Kind  string    `json:"kind"`
+	DueAt time.Time `json:"due_at"`
+	// MetAt is when the report was responded to or resolved, nil while it
+	// was not.
+	MetAt *time.Time `json:"met_at"`
+	// RemainingSeconds is the business time left when the SLA was met, or
+	// now while it was not, negative once overdue.
+	RemainingSeconds int64 `json:"remaining_seconds"`
+	Breached         bool  `json:"breached"`
+}
+
+// escalateBatchSize is the number of breaches handled per query.
+const escalateBatchSize = 100
+
+type SLAService interface {
+	Escalate() (int, error)
+	GetEscalations(currentUserID uuid.UUID, role string, req EscalationsRequest) ([]db.GetEscalationsRow, error)
+	GetReportSLA(currentUserID uuid.UUID, role string, reportID uuid.UUID) ([]Status, error)
+}
+
+type service struct {
+	repo            SLARepository
+	calendarService calendar.CalendarService
+	userAreaService userareas.UserAreasService
+	notifications   notifications.NotificationsService
+}
+
+// NewSLAService returns a service that checks every SLA_CHECK_INTERVAL for
+// reports past the response or resolution deadline of their category, and
+// escalates them to the officials of the parent area from a background
+// goroutine. Deadlines count business time in the calendar of the report's
+// area.
+func NewSLAService(repo SLARepository, calendarService calendar.CalendarService, userAreaService userareas.UserAreasService, notificationService notifications.NotificationsService) SLAService {
+	viper.SetDefault("SLA_CHECK_INTERVAL", "5m")
+
+	s := &service{
+		repo:            repo,
+		calendarService: calendarService,
+		userAreaService: userAreaService,
+		notifications:   notificationService,
+	}
+
+	interval := viper.GetDuration("SLA_CHECK_INTERVAL")
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	go s.run(interval)
+
+	return s
+}
+
+// Escalate escalates every breach that was not escalated yet and returns how
+// many it escalated. A report whose area has no parent is escalated to the
+// admins.
+func (s *service) Escalate() (int, error) {
+	now := time.Now()
+	calendars := make(map[pgtype.UUID]calendar.Calendar)
+	arg := db.GetSLACandidatesParams{LimitCount: escalateBatchSize}
+	total := 0
+
+	for {
+		candidates, err := s.repo.GetSLACandidates(arg)
+		if err != nil {
+			return total, err
+		}
+
+		for _, candidate := range candidates {
+			cal, ok := calendars[candidate.AreaID]
+			if !ok {
+				cal, err = s.calendarService.ForArea(candidate.AreaID)
+				if err != nil {
+					return total, err
+				}
+				calendars[candidate.AreaID] = cal
+			}
+
+			due, err := cal.Add(candidate.CreatedAt, time.Duration(candidate.SlaMinutes)*time.Minute)
+			if err != nil {
+				if !errors.Is(err, calendar.ErrNoWorkingTime) {
+					return total, err
+				}
+				// a calendar without working hours counts wall-clock time
+				due = candidate.Deadline
+			}
+			if due.After(now) {
+				// not due in business time yet, skip the report until it is
+				if err := s.repo.SetSLADeadline(db.SetSLADeadlineParams{
+					ReportID:   candidate.ReportID,
+					Kind:       candidate.Kind,
+					SlaMinutes: candidate.SlaMinutes,
+					DueAt:      due,
+				}); err != nil {
+					return total, err
+				}
+				continue
+			}
+
+			escalated, err := s.repo.Escalate(db.CreateReportEscalationParams{
+				ReportID: candidate.ReportID,
+				Kind:     candidate.Kind,
+				AreaID:   candidate.EscalateTo,
+				Deadline: due,
+			}, escalationRemark(candidate))
+			if err != nil {
+				return total, err
+			}
+			if escalated {
+				total++
+				s.notifyEscalated(candidate, due)
+			}
+		}
+
+		if len(candidates) < escalateBatchSize {
+			return total, nil
+		}
+
+		last := candidates[len(candidates)-1]
+		arg.AfterDeadline = last.Deadline
+		arg.AfterReportID = last.ReportID
+		arg.AfterKind = last.Kind
+	}
+}
+
+// GetEscalations lists escalations, newest first. Officials only see the
+// escalations sent to areas in their jurisdiction.
+func (s *service) GetEscalations(currentUserID uuid.UUID, role string, req EscalationsRequest) ([]db.GetEscalationsRow, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	if req.Kind != "" && req.Kind != string(pkg.EscalationResponse) && req.Kind != string(pkg.EscalationResolution) {
+		return nil, ErrInvalidKind
+	}
+
+	var areaIDs []uuid.UUID
+	if role == string(pkg.RoleOfficial) {
+		ids, err := s.userAreaService.GetJurisdiction(currentUserID)
+		if err != nil {
+			return nil, err
+		}
+		areaIDs = ids
+	}
+
+	return s.repo.GetEscalations(db.GetEscalationsParams{
+		AreaIds: areaIDs,
+		Kind: pgtype.Text{
+			String: req.Kind,
+			Valid:  req.Kind != "",
+		},
+		OpenOnly:    req.OpenOnly,
+		OffsetCount: int32((req.Page - 1) * req.Limit),
+		LimitCount:  int32(req.Limit),
+	})
+}
+
+// GetReportSLA returns how a report stands against the SLAs of its category.
+// Officials only see reports in their jurisdiction.
+func (s *service) GetReportSLA(currentUserID uuid.UUID, role string, reportID uuid.UUID) ([]Status, error) {
+	report, err := s.repo.GetReportSLA(reportID)
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return nil, reports.ErrReportNotFound
+		}
+		return nil, err
+	}
+
+	if role == string(pkg.RoleOfficial) {
+		areaIDs, err := s.userAreaService.GetJurisdiction(currentUserID)
+		if err != nil {
+			return nil, err
+		}
+		if !inJurisdiction(areaIDs, report.AreaID) {
+			return nil, reports.ErrReportNotFound
+		}
+	}
+
+	cal, err := s.calendarService.ForArea(report.AreaID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := []Status{}
+
+	for _, sla := range []struct {
+		kind    pkg.EscalationKind
+		minutes pgtype.Int4
+		metAt   pgtype.Timestamptz
+	}{
+		{pkg.EscalationResponse, report.ResponseSlaMinutes, report.RespondedAt},
+		{pkg.EscalationResolution, report.ResolutionSlaMinutes, report.ResolvedAt},
+	} {
+		if !sla.minutes.Valid {
+			continue
+		}
+
+		elapsed := cal.Elapsed
+		due, err := cal.Add(report.CreatedAt, time.Duration(sla.minutes.Int32)*time.Minute)
+		if err != nil {
+			if !errors.Is(err, calendar.ErrNoWorkingTime) {
+				return nil, err
+			}
+			// a calendar without working hours counts wall-clock time,
+			// like Escalate does
+			due = report.CreatedAt.Add(time.Duration(sla.minutes.Int32) * time.Minute)
+			elapsed = func(from, to time.Time) time.Duration { return to.Sub(from) }
+		}
+
+		status := Status{Kind: string(sla.kind), DueAt: due}
+
+		measuredAt := now
+		if sla.metAt.Valid {
+			metAt := sla.metAt.Time
+			status.MetAt = &metAt
+			measuredAt = metAt
+		}
+
+		if measuredAt.After(due) {
+			status.Breached = true
+			status.RemainingSeconds = -int64(elapsed(due, measuredAt) / time.Second)
+		} else {
+			status.RemainingSeconds = int64(elapsed(measuredAt, due) / time.Second)
+		}
+
+		result = append(result, status)
+	}
+
+	return result, nil
+}
+
+// inJurisdiction reports whether areaID is one of areaIDs.
+func inJurisdiction(areaIDs []uuid.UUID, areaID pgtype.UUID) bool {
+	if !areaID.Valid {
+		return false
+	}
+	for _, id := range areaIDs {
+		if id == uuid.UUID(areaID.Bytes) {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyEscalated tells the officials a breach was escalated to, or the
+// admins when it went to them. Failures are only logged, the escalation
+// itself already stands.
+func (s *service) notifyEscalated(breach db.GetSLACandidatesRow, due time.Time) {
+	recipients, err := s.repo.GetEscalationRecipients(breach.EscalateTo)
+	if err == nil {
+		_, err = s.notifications.Notify(notifications.Event{
+			Type:       pkg.NotificationReportEscalated,
+			Recipients: recipients,
+			ReportID:   breach.ReportID,
+			Data: map[string]interface{}{
+				"kind":     breach.Kind,
+				"deadline": due,
+			},
+		})
+	}
+	if err != nil {
+		log.Printf("failed to notify escalation of report %s: %v", breach.ReportID, err)
+	}
+}
+
+func escalationRemark(breach db.GetSLACandidatesRow) string {
+	to := "admins"
+	if breach.EscalateTo.Valid {
+		to = "officials of " + breach.EscalateToName.String
+	}
+	return fmt.Sprintf("%s SLA breached, escalated to %s", breach.Kind, to)
+}
+
+func (s *service) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := s.Escalate(); err != nil {
+			log.Printf("Failed to escalate SLA breaches: %v", err)
+		}
+	}
+}
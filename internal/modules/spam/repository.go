@@ -0,0 +1,195 @@
+package spam
+
+import (
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SpamRepository interface {
+	FlagReport(arg db.CreateReportSpamFlagParams, threshold float64, check func(report db.GetReportForUpdateRow) error) (FlagResult, error)
+	ReviewReport(reportID uuid.UUID, decision pkg.SpamDecision, reviewer uuid.UUID) (ReviewResult, error)
+	GetQueue(arg db.GetSpamQueueParams) ([]db.GetSpamQueueRow, error)
+}
+
+type repository struct {
+	pool *pgxpool.Pool
+	db   *db.Queries
+}
+
+func NewSpamRepository(pool *pgxpool.Pool) SpamRepository {
+	return &repository{pool: pool, db: db.New(pool)}
+}
+
+// withTx runs fn inside a single database transaction.
+// The transaction is committed only when fn returns nil.
+func (r *repository) withTx(fn func(q *db.Queries) error) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.db.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// FlagReport stores a flag with the report row locked, then hides the report
+// once the weight of its unreviewed flags reaches threshold. Locking the
+// report makes concurrent flags see each other, so the report is hidden
+// exactly once. check is called with the locked report before the flag is
+// written.
+func (r *repository) FlagReport(arg db.CreateReportSpamFlagParams, threshold float64, check func(report db.GetReportForUpdateRow) error) (FlagResult, error) {
+	var result FlagResult
+
+	err := r.withTx(func(q *db.Queries) error {
+		ctx := context.Background()
+
+		report, err := q.GetReportForUpdate(ctx, arg.ReportID)
+		if err != nil {
+			return err
+		}
+
+		if err := check(report); err != nil {
+			return err
+		}
+
+		result.Flag, err = q.CreateReportSpamFlag(ctx, arg)
+		if err != nil {
+			if err.Error() == pkg.ErrNoRows {
+				return ErrAlreadyFlagged
+			}
+			return err
+		}
+
+		result.Weight, err = q.GetReportSpamWeight(ctx, arg.ReportID)
+		if err != nil {
+			return err
+		}
+
+		if result.Weight < threshold || !canAutoHide(report.Status) {
+			return nil
+		}
+
+		if err := q.UpdateReportStatus(ctx, db.UpdateReportStatusParams{
+			Status: string(pkg.ReportHidden),
+			ID:     arg.ReportID,
+		}); err != nil {
+			return err
+		}
+
+		// no changed_by, the community hid it
+		_, err = q.CreateReportStatusHistory(ctx, db.CreateReportStatusHistoryParams{
+			ReportID:  arg.ReportID,
+			OldStatus: report.Status,
+			NewStatus: string(pkg.ReportHidden),
+			Remark: pgtype.Text{
+				String: "hidden automatically after community spam flags",
+				Valid:  true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Hidden = true
+		return nil
+	})
+
+	return result, err
+}
+
+// ReviewReport applies a moderator decision on the pending flags of a report.
+// Confirming hides the report, restoring puts a hidden report back to the
+// status it had before it was hidden. Either way the pending flags are closed
+// so they no longer count towards the threshold.
+func (r *repository) ReviewReport(reportID uuid.UUID, decision pkg.SpamDecision, reviewer uuid.UUID) (ReviewResult, error) {
+	var result ReviewResult
+
+	err := r.withTx(func(q *db.Queries) error {
+		ctx := context.Background()
+
+		report, err := q.GetReportForUpdate(ctx, reportID)
+		if err != nil {
+			return err
+		}
+
+		result = ReviewResult{
+			ReportID:  reportID,
+			UserID:    report.UserID,
+			Decision:  decision,
+			OldStatus: report.Status,
+			NewStatus: report.Status,
+		}
+
+		var remark string
+
+		switch {
+		case decision == pkg.SpamConfirmed && report.Status != string(pkg.ReportHidden):
+			result.NewStatus = string(pkg.ReportHidden)
+			remark = "confirmed as spam by a moderator"
+		// merged reports stay hidden behind their target
+		case decision == pkg.SpamRestored && report.Status == string(pkg.ReportHidden) && !report.MergedIntoID.Valid:
+			previous, err := q.GetStatusBeforeHidden(ctx, reportID)
+			if err != nil && err.Error() != pkg.ErrNoRows {
+				return err
+			}
+			if !canAutoHide(previous) {
+				previous = string(pkg.ReportOpen)
+			}
+			result.NewStatus = previous
+			remark = "restored by a moderator after spam review"
+		}
+
+		if result.NewStatus != report.Status {
+			if err := q.UpdateReportStatus(ctx, db.UpdateReportStatusParams{
+				Status: result.NewStatus,
+				ID:     reportID,
+			}); err != nil {
+				return err
+			}
+
+			if _, err := q.CreateReportStatusHistory(ctx, db.CreateReportStatusHistoryParams{
+				ReportID:  reportID,
+				OldStatus: report.Status,
+				NewStatus: result.NewStatus,
+				Remark: pgtype.Text{
+					String: remark,
+					Valid:  true,
+				},
+				ChangedBy: pgtype.UUID{
+					Bytes: reviewer,
+					Valid: true,
+				},
+			}); err != nil {
+				return err
+			}
+		}
+
+		return q.MarkReportSpamReviewed(ctx, db.MarkReportSpamReviewedParams{
+			Decision: string(decision),
+			ID:       reportID,
+		})
+	})
+
+	return result, err
+}
+
+func (r *repository) GetQueue(arg db.GetSpamQueueParams) ([]db.GetSpamQueueRow, error) {
+	return r.db.GetSpamQueue(context.Background(), arg)
+}
+
+// canAutoHide reports whether a report in status may be hidden by flags, and
+// so also whether a restored report may go back to it.
+func canAutoHide(status string) bool {
+	return status == string(pkg.ReportUnderReview) || status == string(pkg.ReportOpen)
+}
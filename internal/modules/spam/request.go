@@ -0,0 +1,5 @@
+package spam
+
+type FlagReportRequest struct {
+	Reason string `json:"reason" form:"reason" validate:"required,oneof=spam offensive misleading duplicate irrelevant"`
+}
@@ -0,0 +1,194 @@
+package spam
+
+import (
+	"encoding/json"
+	"errors"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/internal/modules/auditlogs"
+	"hubku/lapor_warga_be_v2/internal/modules/credibility"
+	"hubku/lapor_warga_be_v2/internal/modules/reports"
+	"hubku/lapor_warga_be_v2/pkg"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+var (
+	ErrReportNotFound = errors.New("report not found")
+	ErrOwnReport      = errors.New("cannot flag your own report")
+	ErrReportHidden   = errors.New("report is already hidden")
+	ErrAlreadyFlagged = errors.New("report already flagged by this user")
+)
+
+type FlagResult struct {
+	Flag   db.ReportSpamFlag
+	Weight float64
+	Hidden bool
+}
+
+type ReviewResult struct {
+	ReportID  uuid.UUID        `json:"report_id"`
+	UserID    uuid.UUID        `json:"user_id"`
+	Decision  pkg.SpamDecision `json:"decision"`
+	OldStatus string           `json:"old_status"`
+	NewStatus string           `json:"new_status"`
+}
+
+type SpamService interface {
+	FlagReport(actor reports.Actor, reportID uuid.UUID, req FlagReportRequest) (db.ReportSpamFlag, error)
+	GetQueue(page, limit int) ([]db.GetSpamQueueRow, error)
+	RestoreReport(actor reports.Actor, reportID uuid.UUID) (ReviewResult, error)
+	ConfirmSpam(actor reports.Actor, reportID uuid.UUID) (ReviewResult, error)
+}
+
+type service struct {
+	repo        SpamRepository
+	credibility credibility.CredibilityService
+	logService  auditlogs.LogsService
+	threshold   float64
+}
+
+// NewSpamService hides a report once the credibility scores of its flaggers
+// add up to SPAM_HIDE_THRESHOLD. With the default of 250, five flags from
+// users at the starting score of 50 are enough.
+func NewSpamService(repo SpamRepository, credibilityService credibility.CredibilityService, logService auditlogs.LogsService) SpamService {
+	viper.SetDefault("SPAM_HIDE_THRESHOLD", 250)
+
+	return &service{
+		repo:        repo,
+		credibility: credibilityService,
+		logService:  logService,
+		threshold:   viper.GetFloat64("SPAM_HIDE_THRESHOLD"),
+	}
+}
+
+func (s *service) FlagReport(actor reports.Actor, reportID uuid.UUID, req FlagReportRequest) (db.ReportSpamFlag, error) {
+	result, err := s.repo.FlagReport(db.CreateReportSpamFlagParams{
+		ReportID: reportID,
+		UserID:   actor.ID,
+		Reason:   req.Reason,
+	}, s.threshold, func(report db.GetReportForUpdateRow) error {
+		if report.Status == string(pkg.ReportHidden) {
+			return ErrReportHidden
+		}
+
+		if report.UserID == actor.ID {
+			return ErrOwnReport
+		}
+
+		// reports still under review are invisible to other citizens
+		if actor.Role == pkg.RoleCitizen &&
+			report.Status != string(pkg.ReportOpen) && report.Status != string(pkg.ReportResolved) {
+			return ErrReportNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return db.ReportSpamFlag{}, ErrReportNotFound
+		}
+		return db.ReportSpamFlag{}, err
+	}
+
+	// log automatic hiding, performed by the flag that crossed the threshold.
+	// Nobody reviewed the hide yet, so the owner is only penalized once a
+	// moderator confirms the spam.
+	if result.Hidden {
+		go func() {
+			metadata, _ := json.Marshal(map[string]interface{}{
+				"new_status": pkg.ReportHidden,
+				"weight":     result.Weight,
+				"threshold":  s.threshold,
+			})
+
+			s.logService.CreateLog(db.CreateAuditLogParams{
+				EntityName:  string(pkg.LogEntityReports),
+				Action:      string(pkg.LogTypeUpdate),
+				Metadata:    json.RawMessage(metadata),
+				EntityID:    reportID,
+				PerformedBy: actor.ID,
+			})
+		}()
+	}
+
+	return result.Flag, nil
+}
+
+func (s *service) GetQueue(page, limit int) ([]db.GetSpamQueueRow, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if limit <= 0 {
+		limit = 20
+	}
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	return s.repo.GetQueue(db.GetSpamQueueParams{
+		OffsetCount: int32((page - 1) * limit),
+		LimitCount:  int32(limit),
+	})
+}
+
+func (s *service) RestoreReport(actor reports.Actor, reportID uuid.UUID) (ReviewResult, error) {
+	return s.review(actor, reportID, pkg.SpamRestored, pkg.LogTypeRestore)
+}
+
+func (s *service) ConfirmSpam(actor reports.Actor, reportID uuid.UUID) (ReviewResult, error) {
+	result, err := s.review(actor, reportID, pkg.SpamConfirmed, pkg.LogTypeConfirm)
+	if err != nil {
+		return ReviewResult{}, err
+	}
+
+	s.score(credibility.Event{
+		Type:     pkg.CredibilitySpamConfirmed,
+		UserID:   result.UserID,
+		ReportID: reportID,
+		ActorID:  actor.ID,
+	})
+
+	return result, nil
+}
+
+func (s *service) review(actor reports.Actor, reportID uuid.UUID, decision pkg.SpamDecision, action pkg.LogType) (ReviewResult, error) {
+	result, err := s.repo.ReviewReport(reportID, decision, actor.ID)
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return ReviewResult{}, ErrReportNotFound
+		}
+		return ReviewResult{}, err
+	}
+
+	// log spam decision
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"decision":   result.Decision,
+			"old_status": result.OldStatus,
+			"new_status": result.NewStatus,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityReports),
+			Action:      string(action),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    reportID,
+			PerformedBy: actor.ID,
+		})
+	}()
+
+	return result, nil
+}
+
+// score records a credibility event of the reporter in the background.
+func (s *service) score(event credibility.Event) {
+	go func() {
+		if err := s.credibility.Record(event); err != nil {
+			log.Printf("failed to score %s on report %s: %v", event.Type, event.ReportID, err)
+		}
+	}()
+}
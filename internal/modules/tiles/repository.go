@@ -0,0 +1,29 @@
+package tiles
+
+import (
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TilesRepository interface {
+	GetAreaTile(arg db.GetAreaTileParams) ([]byte, error)
+	GetReportTile(arg db.GetReportTileParams) ([]byte, error)
+}
+
+type repository struct {
+	db *db.Queries
+}
+
+func NewTilesRepository(pool *pgxpool.Pool) TilesRepository {
+	return &repository{db: db.New(pool)}
+}
+
+func (r *repository) GetAreaTile(arg db.GetAreaTileParams) ([]byte, error) {
+	return r.db.GetAreaTile(context.Background(), arg)
+}
+
+func (r *repository) GetReportTile(arg db.GetReportTileParams) ([]byte, error) {
+	return r.db.GetReportTile(context.Background(), arg)
+}
@@ -0,0 +1,98 @@
+package tiles
+
+import (
+	"errors"
+	"fmt"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	userareas "hubku/lapor_warga_be_v2/internal/modules/user_areas"
+	"hubku/lapor_warga_be_v2/pkg"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+var (
+	ErrUnknownLayer = errors.New("unknown tile layer")
+	ErrInvalidTile  = errors.New("invalid tile coordinates")
+)
+
+const (
+	LayerAreas   = "areas"
+	LayerReports = "reports"
+
+	maxZoom = 22
+)
+
+type TilesService interface {
+	GetTile(currentUserID uuid.UUID, role string, layer string, z, x, y int) ([]byte, error)
+	CacheControl(role string, layer string) string
+}
+
+type service struct {
+	repo            TilesRepository
+	userAreaService userareas.UserAreasService
+	maxAge          int
+}
+
+func NewTilesService(repo TilesRepository, userAreaService userareas.UserAreasService) TilesService {
+	viper.SetDefault("TILE_CACHE_MAX_AGE", 300)
+
+	return &service{
+		repo:            repo,
+		userAreaService: userAreaService,
+		maxAge:          viper.GetInt("TILE_CACHE_MAX_AGE"),
+	}
+}
+
+// GetTile renders tile z/x/y of layer as a Mapbox Vector Tile. An empty tile
+// is returned as an empty body. Officials only get the reports of their
+// jurisdiction.
+func (s *service) GetTile(currentUserID uuid.UUID, role string, layer string, z, x, y int) ([]byte, error) {
+	if z < 0 || z > maxZoom {
+		return nil, ErrInvalidTile
+	}
+
+	// a zoom level has 2^z tiles per axis
+	n := 1 << z
+	if x < 0 || x >= n || y < 0 || y >= n {
+		return nil, ErrInvalidTile
+	}
+
+	switch layer {
+	case LayerAreas:
+		return s.repo.GetAreaTile(db.GetAreaTileParams{
+			Z: int32(z),
+			X: int32(x),
+			Y: int32(y),
+		})
+	case LayerReports:
+		var areaIDs []uuid.UUID
+		if role == string(pkg.RoleOfficial) {
+			var err error
+			areaIDs, err = s.userAreaService.GetJurisdiction(currentUserID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return s.repo.GetReportTile(db.GetReportTileParams{
+			Z:          int32(z),
+			X:          int32(x),
+			Y:          int32(y),
+			PublicOnly: role == string(pkg.RoleCitizen),
+			AreaIds:    areaIDs,
+		})
+	}
+
+	return nil, ErrUnknownLayer
+}
+
+// CacheControl returns the Cache-Control header for a tile. Tiles that are the
+// same for every user may be kept by a CDN; the reports layer of moderators
+// also holds reports citizens cannot see, so it stays in the browser cache.
+func (s *service) CacheControl(role string, layer string) string {
+	if layer == LayerAreas || role == string(pkg.RoleCitizen) {
+		return fmt.Sprintf("public, max-age=%d, s-maxage=%d", s.maxAge, s.maxAge)
+	}
+	return fmt.Sprintf("private, max-age=%d", s.maxAge)
+}
@@ -0,0 +1,40 @@
+package userareas
+
+import (
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UserAreasRepository interface {
+	AssignUserArea(arg db.AssignUserAreaParams) (db.UserArea, error)
+	UnassignUserArea(arg db.UnassignUserAreaParams) (uuid.UUID, error)
+	GetUserAreas(userID uuid.UUID) ([]db.GetUserAreasRow, error)
+	GetUserAreaTree(userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type repository struct {
+	db *db.Queries
+}
+
+func NewUserAreasRepository(pool *pgxpool.Pool) UserAreasRepository {
+	return &repository{db: db.New(pool)}
+}
+
+func (r *repository) AssignUserArea(arg db.AssignUserAreaParams) (db.UserArea, error) {
+	return r.db.AssignUserArea(context.Background(), arg)
+}
+
+func (r *repository) UnassignUserArea(arg db.UnassignUserAreaParams) (uuid.UUID, error) {
+	return r.db.UnassignUserArea(context.Background(), arg)
+}
+
+func (r *repository) GetUserAreas(userID uuid.UUID) ([]db.GetUserAreasRow, error) {
+	return r.db.GetUserAreas(context.Background(), userID)
+}
+
+func (r *repository) GetUserAreaTree(userID uuid.UUID) ([]uuid.UUID, error) {
+	return r.db.GetUserAreaTree(context.Background(), userID)
+}
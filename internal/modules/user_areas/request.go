@@ -0,0 +1,7 @@
+package userareas
+
+import "github.com/google/uuid"
+
+type AssignAreaRequest struct {
+	AreaID uuid.UUID `json:"area_id" form:"area_id" validate:"required"`
+}
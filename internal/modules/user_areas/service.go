@@ -0,0 +1,127 @@
+package userareas
+
+import (
+	"encoding/json"
+	"errors"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/internal/modules/auditlogs"
+	userroles "hubku/lapor_warga_be_v2/internal/modules/user_roles"
+	"hubku/lapor_warga_be_v2/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrNotOfficial  = errors.New("user is not an official")
+	ErrAreaNotFound = errors.New("area not found")
+	ErrNotAssigned  = errors.New("area is not assigned to this user")
+)
+
+type UserAreasService interface {
+	AssignArea(currentUserID uuid.UUID, userID uuid.UUID, areaID uuid.UUID) (db.UserArea, error)
+	UnassignArea(currentUserID uuid.UUID, userID uuid.UUID, areaID uuid.UUID) error
+	GetUserAreas(userID uuid.UUID) ([]db.GetUserAreasRow, error)
+	GetJurisdiction(userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type service struct {
+	repo             UserAreasRepository
+	userRolesService userroles.UserRolesService
+	logService       auditlogs.LogsService
+}
+
+func NewUserAreasService(repo UserAreasRepository, userRolesService userroles.UserRolesService, logService auditlogs.LogsService) UserAreasService {
+	return &service{
+		repo:             repo,
+		userRolesService: userRolesService,
+		logService:       logService,
+	}
+}
+
+// AssignArea puts an area, and through it all of its descendants, under the
+// jurisdiction of an official.
+func (s *service) AssignArea(currentUserID uuid.UUID, userID uuid.UUID, areaID uuid.UUID) (db.UserArea, error) {
+	isOfficial, err := s.userRolesService.HasRole(db.HasRoleParams{
+		UserID:   userID,
+		RoleName: string(pkg.RoleOfficial),
+	})
+	if err != nil {
+		return db.UserArea{}, err
+	}
+	if !isOfficial {
+		return db.UserArea{}, ErrNotOfficial
+	}
+
+	result, err := s.repo.AssignUserArea(db.AssignUserAreaParams{
+		UserID: userID,
+		AssignedBy: pgtype.UUID{
+			Bytes: currentUserID,
+			Valid: true,
+		},
+		AreaID: areaID,
+	})
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return db.UserArea{}, ErrAreaNotFound
+		}
+		return db.UserArea{}, err
+	}
+
+	// log assign area
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"area_id": areaID,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityUsers),
+			Action:      string(pkg.LogTypeAssign),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    userID,
+			PerformedBy: currentUserID,
+		})
+	}()
+
+	return result, nil
+}
+
+func (s *service) UnassignArea(currentUserID uuid.UUID, userID uuid.UUID, areaID uuid.UUID) error {
+	_, err := s.repo.UnassignUserArea(db.UnassignUserAreaParams{
+		UserID: userID,
+		AreaID: areaID,
+	})
+	if err != nil {
+		if err.Error() == pkg.ErrNoRows {
+			return ErrNotAssigned
+		}
+		return err
+	}
+
+	// log unassign area
+	go func() {
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"area_id": areaID,
+		})
+
+		s.logService.CreateLog(db.CreateAuditLogParams{
+			EntityName:  string(pkg.LogEntityUsers),
+			Action:      string(pkg.LogTypeUnassign),
+			Metadata:    json.RawMessage(metadata),
+			EntityID:    userID,
+			PerformedBy: currentUserID,
+		})
+	}()
+
+	return nil
+}
+
+func (s *service) GetUserAreas(userID uuid.UUID) ([]db.GetUserAreasRow, error) {
+	return s.repo.GetUserAreas(userID)
+}
+
+// GetJurisdiction returns the ids of the areas assigned to the user and of
+// every area below them through areas.parent_id.
+func (s *service) GetJurisdiction(userID uuid.UUID) ([]uuid.UUID, error) {
+	return s.repo.GetUserAreaTree(userID)
+}
@@ -0,0 +1,24 @@
+package views
+
+import (
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ViewsRepository interface {
+	FlushViews(arg db.FlushReportViewsParams) error
+}
+
+type repository struct {
+	db *db.Queries
+}
+
+func NewViewsRepository(pool *pgxpool.Pool) ViewsRepository {
+	return &repository{db: db.New(pool)}
+}
+
+func (r *repository) FlushViews(arg db.FlushReportViewsParams) error {
+	return r.db.FlushReportViews(context.Background(), arg)
+}
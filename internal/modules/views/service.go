@@ -0,0 +1,161 @@
+package views
+
+import (
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+type ViewsService interface {
+	RecordView(reportID, userID uuid.UUID, sessionID string)
+	Flush() error
+}
+
+// viewKey identifies a view, a session counts once per report.
+type viewKey struct {
+	ReportID  uuid.UUID
+	SessionID uuid.UUID
+}
+
+type viewEntry struct {
+	UserID   uuid.UUID
+	ViewedAt time.Time
+}
+
+type service struct {
+	repo    ViewsRepository
+	maxSize int
+
+	mu     sync.Mutex
+	buffer map[viewKey]viewEntry
+
+	// flushing serializes flushes, so a periodic and an early flush never
+	// write the same batch twice
+	flushing sync.Mutex
+	trigger  chan struct{}
+}
+
+// NewViewsService returns a service that buffers report views in memory and
+// writes them to the database in batches from a background goroutine, every
+// VIEW_FLUSH_INTERVAL or as soon as VIEW_BUFFER_SIZE views are waiting.
+func NewViewsService(repo ViewsRepository) ViewsService {
+	viper.SetDefault("VIEW_FLUSH_INTERVAL", "30s")
+	viper.SetDefault("VIEW_BUFFER_SIZE", 5000)
+
+	s := &service{
+		repo:    repo,
+		maxSize: viper.GetInt("VIEW_BUFFER_SIZE"),
+		buffer:  make(map[viewKey]viewEntry),
+		trigger: make(chan struct{}, 1),
+	}
+
+	interval := viper.GetDuration("VIEW_FLUSH_INTERVAL")
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	go s.run(interval)
+
+	return s
+}
+
+// RecordView buffers a view of reportID. It never touches the database, so
+// it is cheap enough to call on every report detail request. When the client
+// does not send a session id, views of the same user are grouped per day.
+func (s *service) RecordView(reportID, userID uuid.UUID, sessionID string) {
+	session, err := uuid.Parse(sessionID)
+	if err != nil {
+		day := time.Now().UTC().Format(time.DateOnly)
+		session = uuid.NewSHA1(userID, []byte(day))
+	}
+
+	key := viewKey{ReportID: reportID, SessionID: session}
+
+	s.mu.Lock()
+	if _, ok := s.buffer[key]; !ok {
+		s.buffer[key] = viewEntry{UserID: userID, ViewedAt: time.Now()}
+	}
+	full := len(s.buffer) >= s.maxSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.trigger <- struct{}{}:
+		default:
+			// a flush is already pending
+		}
+	}
+}
+
+// Flush writes all buffered views. Views that could not be written are put
+// back in the buffer and retried on the next flush.
+func (s *service) Flush() error {
+	s.flushing.Lock()
+	defer s.flushing.Unlock()
+
+	s.mu.Lock()
+	batch := s.buffer
+	s.buffer = make(map[viewKey]viewEntry, len(batch))
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	arg := db.FlushReportViewsParams{
+		ReportIds:  make([]uuid.UUID, 0, len(batch)),
+		SessionIds: make([]uuid.UUID, 0, len(batch)),
+		UserIds:    make([]uuid.UUID, 0, len(batch)),
+		ViewedAts:  make([]time.Time, 0, len(batch)),
+	}
+
+	for key, entry := range batch {
+		arg.ReportIds = append(arg.ReportIds, key.ReportID)
+		arg.SessionIds = append(arg.SessionIds, key.SessionID)
+		arg.UserIds = append(arg.UserIds, entry.UserID)
+		arg.ViewedAts = append(arg.ViewedAts, entry.ViewedAt)
+	}
+
+	if err := s.repo.FlushViews(arg); err != nil {
+		s.requeue(batch)
+		return err
+	}
+
+	return nil
+}
+
+// requeue puts a failed batch back, without growing the buffer past twice
+// its size when the database stays unavailable.
+func (s *service) requeue(batch map[viewKey]viewEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, entry := range batch {
+		if len(s.buffer) >= 2*s.maxSize {
+			return
+		}
+		if _, ok := s.buffer[key]; !ok {
+			s.buffer[key] = entry
+		}
+	}
+}
+
+func (s *service) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.trigger:
+		}
+
+		if err := s.Flush(); err != nil {
+			log.Printf("Failed to flush report views: %v", err)
+		}
+	}
+}
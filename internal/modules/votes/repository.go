@@ -0,0 +1,133 @@
+package votes
+
+import (
+	"context"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type VotesRepository interface {
+	SetVote(reportID, userID uuid.UUID, voteType pgtype.Text, check func(report db.GetReportForUpdateRow) error) (db.UpdateReportVoteCountsRow, error)
+}
+
+type repository struct {
+	pool *pgxpool.Pool
+	db   *db.Queries
+}
+
+func NewVotesRepository(pool *pgxpool.Pool) VotesRepository {
+	return &repository{pool: pool, db: db.New(pool)}
+}
+
+// withTx runs fn inside a single database transaction.
+// The transaction is committed only when fn returns nil.
+func (r *repository) withTx(fn func(q *db.Queries) error) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.db.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SetVote replaces the vote of userID on reportID with voteType, or removes
+// it when voteType is not valid. The vote row and the report counters are
+// changed in the same transaction, with the report row locked, so the
+// counters always match the votes. check is called with the locked report
+// before anything is written.
+func (r *repository) SetVote(reportID, userID uuid.UUID, voteType pgtype.Text, check func(report db.GetReportForUpdateRow) error) (db.UpdateReportVoteCountsRow, error) {
+	var counts db.UpdateReportVoteCountsRow
+
+	err := r.withTx(func(q *db.Queries) error {
+		ctx := context.Background()
+
+		report, err := q.GetReportForUpdate(ctx, reportID)
+		if err != nil {
+			return err
+		}
+
+		if err := check(report); err != nil {
+			return err
+		}
+
+		var previous pgtype.Text
+
+		current, err := q.GetReportVote(ctx, db.GetReportVoteParams{
+			ReportID: reportID,
+			UserID:   userID,
+		})
+		if err == nil {
+			previous = pgtype.Text{String: current.VoteType, Valid: true}
+		} else if err.Error() != pkg.ErrNoRows {
+			return err
+		}
+
+		switch {
+		case previous == voteType:
+			// nothing changes, only read the counters back
+		case !voteType.Valid:
+			err = q.DeleteReportVote(ctx, db.DeleteReportVoteParams{
+				ReportID: reportID,
+				UserID:   userID,
+			})
+		case !previous.Valid:
+			_, err = q.CreateReportVote(ctx, db.CreateReportVoteParams{
+				ReportID: reportID,
+				UserID:   userID,
+				VoteType: voteType.String,
+			})
+		default:
+			err = q.UpdateReportVoteType(ctx, db.UpdateReportVoteTypeParams{
+				VoteType: voteType.String,
+				ReportID: reportID,
+				UserID:   userID,
+			})
+		}
+		if err != nil {
+			return err
+		}
+
+		up, down := voteDelta(previous, voteType)
+
+		counts, err = q.UpdateReportVoteCounts(ctx, db.UpdateReportVoteCountsParams{
+			UpvoteDelta:   up,
+			DownvoteDelta: down,
+			ID:            reportID,
+		})
+		return err
+	})
+
+	return counts, err
+}
+
+// voteDelta returns how the upvote and downvote counters change when a vote
+// goes from previous to next (an invalid value meaning no vote).
+func voteDelta(previous, next pgtype.Text) (up int32, down int32) {
+	apply := func(vote pgtype.Text, n int32) {
+		if !vote.Valid {
+			return
+		}
+		switch pkg.VoteType(vote.String) {
+		case pkg.VoteUp:
+			up += n
+		case pkg.VoteDown:
+			down += n
+		}
+	}
+
+	apply(previous, -1)
+	apply(next, 1)
+
+	return up, down
+}
@@ -0,0 +1,5 @@
+package votes
+
+type VoteRequest struct {
+	VoteType string `json:"vote_type" form:"vote_type" validate:"required,oneof=upvote downvote"`
+}
@@ -0,0 +1,146 @@
+package votes
+
+import (
+	"errors"
+	db "hubku/lapor_warga_be_v2/internal/database/generated"
+	"hubku/lapor_warga_be_v2/internal/modules/credibility"
+	"hubku/lapor_warga_be_v2/internal/modules/realtime"
+	"hubku/lapor_warga_be_v2/internal/modules/reports"
+	"hubku/lapor_warga_be_v2/pkg"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrReportNotFound = errors.New("report not found")
+	ErrOwnReport      = errors.New("cannot vote on your own report")
+	ErrReportHidden   = errors.New("cannot vote on a hidden report")
+	ErrInvalidVote    = errors.New("invalid vote type")
+)
+
+type VoteResult struct {
+	ReportID      uuid.UUID   `json:"report_id"`
+	MyVote        pgtype.Text `json:"my_vote"`
+	UpvoteCount   int64       `json:"upvote_count"`
+	DownvoteCount int64       `json:"downvote_count"`
+}
+
+type VotesService interface {
+	Vote(actor reports.Actor, reportID uuid.UUID, voteType pkg.VoteType) (VoteResult, error)
+	RetractVote(actor reports.Actor, reportID uuid.UUID) (VoteResult, error)
+}
+
+type service struct {
+	repo        VotesRepository
+	realtime    realtime.RealtimeService
+	credibility credibility.CredibilityService
+}
+
+func NewVotesService(repo VotesRepository, realtimeService realtime.RealtimeService, credibilityService credibility.CredibilityService) VotesService {
+	return &service{repo: repo, realtime: realtimeService, credibility: credibilityService}
+}
+
+// Vote casts or switches the actor's vote on a report. Voting the same way
+// twice is a no-op.
+func (s *service) Vote(actor reports.Actor, reportID uuid.UUID, voteType pkg.VoteType) (VoteResult, error) {
+	if voteType != pkg.VoteUp && voteType != pkg.VoteDown {
+		return VoteResult{}, ErrInvalidVote
+	}
+
+	vote := pgtype.Text{String: string(voteType), Valid: true}
+
+	var (
+		areaID  pgtype.UUID
+		ownerID uuid.UUID
+	)
+	counts, err := s.repo.SetVote(reportID, actor.ID, vote, func(report db.GetReportForUpdateRow) error {
+		areaID = report.AreaID
+		ownerID = report.UserID
+
+		if report.Status == string(pkg.ReportHidden) {
+			return ErrReportHidden
+		}
+
+		if actor.Role == pkg.RoleCitizen {
+			if report.UserID == actor.ID {
+				return ErrOwnReport
+			}
+
+			// reports still under review are invisible to other citizens
+			if report.Status != string(pkg.ReportOpen) && report.Status != string(pkg.ReportResolved) {
+				return ErrReportNotFound
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return VoteResult{}, mapError(err)
+	}
+
+	s.publishCounts(reportID, areaID, counts)
+
+	// each voter's upvote is scored once, retracting it later does not
+	// take the points back
+	if voteType == pkg.VoteUp && ownerID != actor.ID {
+		go func() {
+			if err := s.credibility.Record(credibility.Event{
+				Type:     pkg.CredibilityUpvoteReceived,
+				UserID:   ownerID,
+				ReportID: reportID,
+				ActorID:  actor.ID,
+			}); err != nil {
+				log.Printf("failed to score upvote on report %s: %v", reportID, err)
+			}
+		}()
+	}
+
+	return newVoteResult(reportID, vote, counts), nil
+}
+
+// RetractVote removes the actor's vote on a report, if any.
+func (s *service) RetractVote(actor reports.Actor, reportID uuid.UUID) (VoteResult, error) {
+	var areaID pgtype.UUID
+	counts, err := s.repo.SetVote(reportID, actor.ID, pgtype.Text{}, func(report db.GetReportForUpdateRow) error {
+		areaID = report.AreaID
+		return nil
+	})
+	if err != nil {
+		return VoteResult{}, mapError(err)
+	}
+
+	s.publishCounts(reportID, areaID, counts)
+
+	return newVoteResult(reportID, pgtype.Text{}, counts), nil
+}
+
+// publishCounts tells live dashboards about the new vote counts of a report.
+func (s *service) publishCounts(reportID uuid.UUID, areaID pgtype.UUID, counts db.UpdateReportVoteCountsRow) {
+	s.realtime.Publish(realtime.Event{
+		Type:     pkg.EventReportVotes,
+		ReportID: reportID,
+		AreaID:   areaID,
+		Data: map[string]interface{}{
+			"upvote_count":   counts.UpvoteCount.Int64,
+			"downvote_count": counts.DownvoteCount.Int64,
+		},
+	})
+}
+
+func newVoteResult(reportID uuid.UUID, vote pgtype.Text, counts db.UpdateReportVoteCountsRow) VoteResult {
+	return VoteResult{
+		ReportID:      reportID,
+		MyVote:        vote,
+		UpvoteCount:   counts.UpvoteCount.Int64,
+		DownvoteCount: counts.DownvoteCount.Int64,
+	}
+}
+
+func mapError(err error) error {
+	if err.Error() == pkg.ErrNoRows {
+		return ErrReportNotFound
+	}
+	return err
+}
@@ -3,13 +3,30 @@ package routes
 import (
 	"hubku/lapor_warga_be_v2/internal/controllers"
 	"hubku/lapor_warga_be_v2/internal/modules/areas"
+	"hubku/lapor_warga_be_v2/internal/modules/attachments"
 	"hubku/lapor_warga_be_v2/internal/modules/auditlogs"
 	"hubku/lapor_warga_be_v2/internal/modules/auth"
+	"hubku/lapor_warga_be_v2/internal/modules/calendar"
 	"hubku/lapor_warga_be_v2/internal/modules/categories"
+	"hubku/lapor_warga_be_v2/internal/modules/comments"
+	"hubku/lapor_warga_be_v2/internal/modules/credibility"
+	"hubku/lapor_warga_be_v2/internal/modules/mail"
+	"hubku/lapor_warga_be_v2/internal/modules/notifications"
+	"hubku/lapor_warga_be_v2/internal/modules/push"
+	"hubku/lapor_warga_be_v2/internal/modules/realtime"
+	"hubku/lapor_warga_be_v2/internal/modules/reports"
+	"hubku/lapor_warga_be_v2/internal/modules/sla"
+	"hubku/lapor_warga_be_v2/internal/modules/spam"
+	"hubku/lapor_warga_be_v2/internal/modules/tiles"
+	userareas "hubku/lapor_warga_be_v2/internal/modules/user_areas"
 	userroles "hubku/lapor_warga_be_v2/internal/modules/user_roles"
 	"hubku/lapor_warga_be_v2/internal/modules/users"
+	"hubku/lapor_warga_be_v2/internal/modules/views"
+	"hubku/lapor_warga_be_v2/internal/modules/votes"
 	"hubku/lapor_warga_be_v2/pkg"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -27,31 +44,104 @@ func Routing(r fiber.Router, db *pgxpool.Pool) {
 
 	validator := validator.New()
 
+	storage, err := attachments.NewStorage()
+	if err != nil {
+		log.Fatal("Failed to initialize attachment storage:", err)
+	}
+
+	// local uploads are served by this server, to holders of a signed URL
+	if local, ok := storage.(*attachments.LocalStorage); ok {
+		uploadsController := controllers.NewUploadsController(local)
+		r.Get(strings.TrimRight(viper.GetString("UPLOAD_BASE_URL"), "/")+"/*", uploadsController.ServeFile)
+	}
+
+	mailer, err := mail.NewMailer()
+	if err != nil {
+		log.Fatal("Failed to initialize mailer:", err)
+	}
+
+	mailTemplates, err := mail.LoadTemplates()
+	if err != nil {
+		log.Fatal("Failed to load email templates:", err)
+	}
+
+	pushProvider, err := push.NewPushProvider()
+	if err != nil {
+		log.Fatal("Failed to initialize push provider:", err)
+	}
+
 	userRepo := users.NewUserRepository(db)
 	roleRepo := userroles.NewUserRolesRepository(db)
+	userAreaRepo := userareas.NewUserAreasRepository(db)
 	logRepo := auditlogs.NewLogsRepository(db)
 	areaRepo := areas.NewAreaRepository(db)
 	categoryRepo := categories.NewCategoriesRepository(db)
+	reportRepo := reports.NewReportsRepository(db)
+	attachmentRepo := attachments.NewAttachmentsRepository(db)
+	voteRepo := votes.NewVotesRepository(db)
+	commentRepo := comments.NewCommentsRepository(db)
+	notificationRepo := notifications.NewNotificationsRepository(db)
+	pushRepo := push.NewPushRepository(db)
+	viewRepo := views.NewViewsRepository(db)
+	spamRepo := spam.NewSpamRepository(db)
+	tileRepo := tiles.NewTilesRepository(db)
+	calendarRepo := calendar.NewCalendarRepository(db)
+	slaRepo := sla.NewSLARepository(db)
+	mailRepo := mail.NewMailRepository(db)
+	realtimeRepo := realtime.NewRealtimeRepository(db)
+	credibilityRepo := credibility.NewCredibilityRepository(db)
 
 	logService := auditlogs.NewLogsService(logRepo)
 	userRolesService := userroles.NewUserRolesService(roleRepo, logService)
 	userService := users.NewUserService(userRepo, userRolesService, logService, encKey)
 	authService := auth.NewAuthService(userService, logService, encKey)
 	areaService := areas.NewAreaService(logService, areaRepo)
+	userAreaService := userareas.NewUserAreasService(userAreaRepo, userRolesService, logService)
 	categoryService := categories.NewCategoriesService(categoryRepo, logService)
+	pushService := push.NewPushService(pushRepo, pushProvider)
+	realtimeService := realtime.NewRealtimeService(realtimeRepo, userAreaService)
+	credibilityService := credibility.NewCredibilityService(credibilityRepo, logService)
+	notificationService := notifications.NewNotificationsService(notificationRepo, pushService)
+	reportService := reports.NewReportsService(reportRepo, areaService, userAreaService, categoryService, notificationService, realtimeService, credibilityService, logService)
+	attachmentService := attachments.NewAttachmentsService(attachmentRepo, storage, reportService, logService)
+	voteService := votes.NewVotesService(voteRepo, realtimeService, credibilityService)
+	commentService := comments.NewCommentsService(commentRepo, reportService, notificationService, realtimeService, logService)
+	viewService := views.NewViewsService(viewRepo)
+	spamService := spam.NewSpamService(spamRepo, credibilityService, logService)
+	tileService := tiles.NewTilesService(tileRepo, userAreaService)
+	calendarService := calendar.NewCalendarService(calendarRepo, logService)
+	mailService := mail.NewMailService(mailRepo, mailer, mailTemplates, logService, encKey)
+	slaService := sla.NewSLAService(slaRepo, calendarService, userAreaService, notificationService)
 
 	logsController := controllers.NewLogsController(logService)
 	userController := controllers.NewUserController(userService, validator)
 	authController := controllers.NewAuthController(authService, validator)
 	userRolesController := controllers.NewUserRolesController(userRolesService, validator)
+	userAreasController := controllers.NewUserAreasController(userAreaService, validator)
 	areaController := controllers.NewAreasController(areaService, validator)
 	categoryController := controllers.NewCategoriesController(categoryService, validator)
+	reportController := controllers.NewReportsController(reportService, viewService, validator)
+	attachmentController := controllers.NewAttachmentsController(attachmentService)
+	voteController := controllers.NewVotesController(voteService, validator)
+	commentController := controllers.NewCommentsController(commentService, validator)
+	spamController := controllers.NewSpamController(spamService, validator)
+	tileController := controllers.NewTilesController(tileService)
+	slaController := controllers.NewSLAController(slaService)
+	calendarController := controllers.NewCalendarsController(calendarService, validator)
+	notificationController := controllers.NewNotificationsController(notificationService)
+	mailController := controllers.NewMailController(mailService)
+	deviceController := controllers.NewDevicesController(pushService, validator)
+	realtimeController := controllers.NewRealtimeController(realtimeService)
+	credibilityController := controllers.NewCredibilityController(credibilityService, validator)
 
 	// Initialize root user
 	if err := userService.InitializeRootUser(); err != nil {
 		log.Fatal("Failed to initialize root user:", err)
 	}
 
+	// suspended users can still read, but not report, vote, flag or comment
+	activeUser := ActiveUserMiddleware(userService)
+
 	// API versioning
 	versioning := r.Group("/api/v1")
 
@@ -66,9 +156,15 @@ func Routing(r fiber.Router, db *pgxpool.Pool) {
 	{
 		userRoutes.Get("/me", userController.GetCurrentUser)
 		userRoutes.Patch("/me", userController.UpdateCurrentUser)
+		userRoutes.Get("/me/credibility", credibilityController.GetMyLedger)
 		userRoutes.Get("/list", RoleMiddleware(string(pkg.RoleAdmin)), userController.GetMasterUser)
 		userRoutes.Post("/create", RoleMiddleware(string(pkg.RoleAdmin)), authController.Register)
 		userRoutes.Get("/search", RoleMiddleware(string(pkg.RoleAdmin)), userController.SearchUser)
+		userRoutes.Get("/areas/:id", RoleMiddleware(string(pkg.RoleAdmin)), userAreasController.GetUserAreas)
+		userRoutes.Post("/areas/:id", RoleMiddleware(string(pkg.RoleAdmin)), userAreasController.AssignArea)
+		userRoutes.Delete("/areas/:id/:area_id", RoleMiddleware(string(pkg.RoleAdmin)), userAreasController.UnassignArea)
+		userRoutes.Get("/credibility/:id", RoleMiddleware(string(pkg.RoleAdmin)), credibilityController.GetUserLedger)
+		userRoutes.Post("/credibility/:id", RoleMiddleware(string(pkg.RoleAdmin)), credibilityController.AdjustScore)
 		userRoutes.Get("/:id", RoleMiddleware(string(pkg.RoleAdmin)), userController.GetUserByID)
 		userRoutes.Post("/restore/:id", RoleMiddleware(string(pkg.RoleAdmin)), userController.RestoreUser)
 		userRoutes.Patch("/:id", RoleMiddleware(string(pkg.RoleAdmin)), userController.UpdateUser)
@@ -110,11 +206,69 @@ func Routing(r fiber.Router, db *pgxpool.Pool) {
 		adminCategoriesRoutes.Get("/search", categoryController.SearchCategories)
 		adminCategoriesRoutes.Get("/slug/:slug", categoryController.GetCategoryBySlug)
 		adminCategoriesRoutes.Post("/toggle-status/:id", categoryController.ToggleCategoryActiveStatus)
+		adminCategoriesRoutes.Put("/sla/:id", categoryController.UpdateCategorySLA)
 		adminCategoriesRoutes.Get("/:id", categoryController.GetCategoryById)
 		adminCategoriesRoutes.Patch("/:id", categoryController.UpdateCategory)
 		adminCategoriesRoutes.Delete("/:id", categoryController.DeleteCategory)
 	}
 
+	// EventSource cannot set headers, so the stream also accepts the access
+	// token cookie. It is registered before the group so the group's
+	// middleware does not reject the request first.
+	versioning.Get("/reports/stream", StreamJWTMiddleware(authService), RoleMiddleware(string(pkg.RoleAdmin), string(pkg.RoleOfficial)), realtimeController.Stream)
+
+	reportsRoutes := versioning.Group("/reports", JWTMiddleware(authService), RoleMiddleware(string(pkg.RoleAdmin), string(pkg.RoleOfficial)))
+	{
+		reportsRoutes.Get("/list", reportController.GetReports)
+		reportsRoutes.Get("/clusters", reportController.GetReportClusters)
+		reportsRoutes.Get("/queue", reportController.GetMyQueue)
+		reportsRoutes.Get("/escalations", slaController.GetEscalations)
+		reportsRoutes.Get("/timeline/:id", reportController.GetReportTimeline)
+		reportsRoutes.Get("/sla/:id", slaController.GetReportSLA)
+		reportsRoutes.Patch("/status/:id", reportController.UpdateReportStatus)
+		reportsRoutes.Post("/merge/:id", reportController.MergeReports)
+		reportsRoutes.Post("/assign/:id", RoleMiddleware(string(pkg.RoleAdmin)), reportController.AssignReport)
+		reportsRoutes.Post("/attachments/:id", attachmentController.UploadAttachments)
+		reportsRoutes.Get("/attachments/:id", attachmentController.GetAttachments)
+		reportsRoutes.Delete("/attachments/file/:id", attachmentController.DeleteAttachment)
+		reportsRoutes.Get("/comments/:id", commentController.GetComments)
+		reportsRoutes.Post("/comments/:id", activeUser, commentController.CreateComment)
+		reportsRoutes.Delete("/comments/item/:id", commentController.DeleteComment)
+		reportsRoutes.Get("/moderation/queue", RoleMiddleware(string(pkg.RoleAdmin)), spamController.GetQueue)
+		reportsRoutes.Post("/moderation/restore/:id", RoleMiddleware(string(pkg.RoleAdmin)), spamController.RestoreReport)
+		reportsRoutes.Post("/moderation/confirm/:id", RoleMiddleware(string(pkg.RoleAdmin)), spamController.ConfirmSpam)
+		reportsRoutes.Get("/:id", reportController.GetReportByID)
+	}
+
+	calendarsRoutes := versioning.Group("/calendars", JWTMiddleware(authService), RoleMiddleware(string(pkg.RoleAdmin)))
+	{
+		calendarsRoutes.Get("/", calendarController.GetCalendar)
+		calendarsRoutes.Put("/hours", calendarController.SetWorkingHours)
+		calendarsRoutes.Delete("/area/:id", calendarController.DeleteAreaCalendar)
+		calendarsRoutes.Post("/holidays", calendarController.AddHoliday)
+		calendarsRoutes.Post("/holidays/import", calendarController.ImportHolidays)
+		calendarsRoutes.Delete("/holidays/:id", calendarController.DeleteHoliday)
+	}
+
+	notificationsRoutes := versioning.Group("/notifications", JWTMiddleware(authService))
+	{
+		notificationsRoutes.Get("/list", notificationController.GetNotifications)
+		notificationsRoutes.Get("/unread-count", notificationController.CountUnread)
+		notificationsRoutes.Patch("/read-all", notificationController.MarkAllRead)
+		notificationsRoutes.Patch("/read/:id", notificationController.MarkRead)
+	}
+
+	mailRoutes := versioning.Group("/mail", JWTMiddleware(authService), RoleMiddleware(string(pkg.RoleAdmin)))
+	{
+		mailRoutes.Get("/outbox", mailController.GetOutbox)
+		mailRoutes.Post("/outbox/retry/:id", mailController.RetryEmail)
+	}
+
+	tilesRoutes := versioning.Group("/tiles", JWTMiddleware(authService), RoleMiddleware(string(pkg.RoleAdmin), string(pkg.RoleOfficial)))
+	{
+		tilesRoutes.Get("/:layer/:z/:x/:y.pbf", tileController.GetTile)
+	}
+
 	/**
 	 * --------------------------------------------------------------------
 	 * Mobile Routes
@@ -132,6 +286,54 @@ func Routing(r fiber.Router, db *pgxpool.Pool) {
 			authRoutes.Post("/login", authController.LoginMobile)
 			authRoutes.Post("/refresh", authController.RefreshMobile)
 		}
+
+		accountRoutes := mobileRoutes.Group("/users", MobileJWTMiddleware(authService))
+		{
+			accountRoutes.Get("/me/credibility", credibilityController.GetMyLedger)
+		}
+
+		tileRoutes := mobileRoutes.Group("/tiles", MobileJWTMiddleware(authService))
+		{
+			tileRoutes.Get("/:layer/:z/:x/:y.pbf", tileController.GetTile)
+		}
+
+		reportRoutes := mobileRoutes.Group("/reports", MobileJWTMiddleware(authService))
+		{
+			reportRoutes.Post("/create", activeUser, reportController.CreateReport)
+			reportRoutes.Post("/duplicates", reportController.FindDuplicates)
+			reportRoutes.Get("/list", reportController.GetReports)
+			reportRoutes.Get("/me", reportController.GetMyReports)
+			reportRoutes.Get("/nearby", reportController.GetNearbyReports)
+			reportRoutes.Get("/clusters", reportController.GetReportClusters)
+			reportRoutes.Get("/feed", reportController.GetFeed)
+			reportRoutes.Get("/timeline/:id", reportController.GetReportTimeline)
+			reportRoutes.Patch("/status/:id", reportController.UpdateReportStatus)
+			reportRoutes.Post("/attachments/:id", attachmentController.UploadAttachments)
+			reportRoutes.Get("/attachments/:id", attachmentController.GetAttachments)
+			reportRoutes.Delete("/attachments/file/:id", attachmentController.DeleteAttachment)
+			reportRoutes.Put("/vote/:id", activeUser, voteController.Vote)
+			reportRoutes.Delete("/vote/:id", voteController.RetractVote)
+			reportRoutes.Post("/flag/:id", activeUser, spamController.FlagReport)
+			reportRoutes.Get("/comments/:id", commentController.GetComments)
+			reportRoutes.Post("/comments/:id", activeUser, commentController.CreateComment)
+			reportRoutes.Patch("/comments/item/:id", activeUser, commentController.UpdateComment)
+			reportRoutes.Delete("/comments/item/:id", commentController.DeleteComment)
+			reportRoutes.Get("/:id", reportController.GetReportByID)
+		}
+
+		notificationRoutes := mobileRoutes.Group("/notifications", MobileJWTMiddleware(authService))
+		{
+			notificationRoutes.Get("/list", notificationController.GetNotifications)
+			notificationRoutes.Get("/unread-count", notificationController.CountUnread)
+			notificationRoutes.Patch("/read-all", notificationController.MarkAllRead)
+			notificationRoutes.Patch("/read/:id", notificationController.MarkRead)
+		}
+
+		deviceRoutes := mobileRoutes.Group("/devices", MobileJWTMiddleware(authService))
+		{
+			deviceRoutes.Post("/register", deviceController.RegisterDevice)
+			deviceRoutes.Post("/unregister", deviceController.UnregisterDevice)
+		}
 	}
 }
 
@@ -156,11 +358,54 @@ func JWTMiddleware(authService auth.AuthService) fiber.Handler {
 		c.Locals("user_id", claims.UserID)
 		c.Locals("username", claims.Username)
 		c.Locals("role", claims.Role)
+		c.Locals("token_expires_at", tokenExpiry(claims))
 
 		return c.Next()
 	}
 }
 
+// StreamJWTMiddleware is JWTMiddleware for server-sent event streams. The
+// browser's EventSource cannot send the token header, so the access token
+// cookie set at login is accepted as well.
+func StreamJWTMiddleware(authService auth.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		accessToken := c.Get(pkg.AccessTokenName)
+		if accessToken == "" {
+			accessToken = c.Cookies(pkg.AccessTokenName)
+		}
+
+		if accessToken == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized",
+			})
+		}
+
+		// Validate token
+		claims, err := authService.ValidateToken(accessToken)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid or expired token",
+			})
+		}
+
+		c.Locals("user_id", claims.UserID)
+		c.Locals("username", claims.Username)
+		c.Locals("role", claims.Role)
+		c.Locals("token_expires_at", tokenExpiry(claims))
+
+		return c.Next()
+	}
+}
+
+// tokenExpiry returns when the token of claims expires, the zero time when it
+// does not.
+func tokenExpiry(claims *auth.Claims) time.Time {
+	if claims.ExpiresAt == nil {
+		return time.Time{}
+	}
+	return claims.ExpiresAt.Time
+}
+
 func MobileMiddleware(c *fiber.Ctx) error {
 	// validate coming from mobile
 	// if not mobile, return not found.
@@ -199,6 +444,7 @@ func MobileJWTMiddleware(authService auth.AuthService) fiber.Handler {
 		c.Locals("user_id", claims.UserID)
 		c.Locals("username", claims.Username)
 		c.Locals("role", claims.Role)
+		c.Locals("token_expires_at", tokenExpiry(claims))
 
 		return c.Next()
 	}
@@ -225,3 +471,25 @@ func RoleMiddleware(allowedRoles ...string) fiber.Handler {
 		})
 	}
 }
+
+// ActiveUserMiddleware stops suspended users from writing. Their access
+// token stays valid until it expires, so the status is read from the
+// database instead of the claims.
+func ActiveUserMiddleware(userService users.UserService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, err := userService.GetUserByIdentifier(cast.ToString(c.Locals("user_id")))
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "User not found",
+			})
+		}
+
+		if user.Status.String == string(pkg.UserSuspended) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Account is suspended",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -7,6 +7,15 @@ type LogType string
 type AreaTolerance string
 type AreaToleranceValue float64
 type JWTTokenType string
+type ReportStatus string
+type VoteType string
+type SpamReason string
+type SpamDecision string
+type EscalationKind string
+type NotificationType string
+type RealtimeEventType string
+type UserStatus string
+type CredibilityEvent string
 
 const (
 	RoleCitizen  RoleType = "citizen"
@@ -14,24 +23,32 @@ const (
 	RoleAdmin    RoleType = "admin"
 
 	// Log Type
-	LogTypeLogin   LogType = "login"
-	LogTypeCreate  LogType = "create"
-	LogTypeUpdate  LogType = "update"
-	LogTypeDelete  LogType = "delete"
-	LogTypeAssign  LogType = "assign"
-	LogTypeRestore LogType = "restore"
+	LogTypeLogin    LogType = "login"
+	LogTypeCreate   LogType = "create"
+	LogTypeUpdate   LogType = "update"
+	LogTypeDelete   LogType = "delete"
+	LogTypeAssign   LogType = "assign"
+	LogTypeUnassign LogType = "unassign"
+	LogTypeRestore  LogType = "restore"
+	LogTypeConfirm  LogType = "confirm"
+	LogTypeMerge    LogType = "merge"
 
 	// Log Entiry
-	LogEntityUsers      LogType = "users"
-	LogEntityRoles      LogType = "roles"
-	LogEntityAreas      LogType = "areas"
-	LogEntityCategories LogType = "categories"
-	LogEntityReports    LogType = "reports"
+	LogEntityUsers       LogType = "users"
+	LogEntityRoles       LogType = "roles"
+	LogEntityAreas       LogType = "areas"
+	LogEntityCategories  LogType = "categories"
+	LogEntityReports     LogType = "reports"
+	LogEntityAttachments LogType = "attachments"
+	LogEntityComments    LogType = "comments"
+	LogEntityCalendars   LogType = "calendars"
+	LogEntityEmails      LogType = "emails"
 
 	// JWT
 	AccessTokenName               = "__asid"
 	RefreshTokenName              = "__rsid"
 	MobileKeyName                 = "X-Request-Tag"
+	SessionIDName                 = "X-Session-ID"
 	RefreshToken     JWTTokenType = "refresh"
 	AccessToken      JWTTokenType = "access"
 	JWTIssuer                     = "lapor_warga"
@@ -44,6 +61,56 @@ const (
 	DetailAreaTolerance AreaToleranceValue = 0.0001
 	OffAreaTolerance    AreaToleranceValue = -99
 
+	// Report Status
+	ReportUnderReview ReportStatus = "under_review"
+	ReportOpen        ReportStatus = "open"
+	ReportResolved    ReportStatus = "resolved"
+	ReportHidden      ReportStatus = "hidden"
+
+	// Vote Type
+	VoteUp   VoteType = "upvote"
+	VoteDown VoteType = "downvote"
+
+	// Spam Flag Reason
+	SpamReasonSpam       SpamReason = "spam"
+	SpamReasonOffensive  SpamReason = "offensive"
+	SpamReasonMisleading SpamReason = "misleading"
+	SpamReasonDuplicate  SpamReason = "duplicate"
+	SpamReasonIrrelevant SpamReason = "irrelevant"
+
+	// Spam Decision
+	SpamRestored  SpamDecision = "restored"
+	SpamConfirmed SpamDecision = "confirmed"
+
+	// SLA Escalation
+	EscalationResponse   EscalationKind = "response"
+	EscalationResolution EscalationKind = "resolution"
+
+	// Notification Type
+	NotificationStatusChanged   NotificationType = "status_changed"
+	NotificationCommentReply    NotificationType = "comment_reply"
+	NotificationReportMerged    NotificationType = "report_merged"
+	NotificationReportAssigned  NotificationType = "report_assigned"
+	NotificationReportEscalated NotificationType = "report_escalated"
+
+	// Realtime Event Type
+	EventReportCreated       RealtimeEventType = "report_created"
+	EventReportStatusChanged RealtimeEventType = "report_status_changed"
+	EventCommentCreated      RealtimeEventType = "comment_created"
+	EventReportVotes         RealtimeEventType = "report_votes"
+
+	// User Status
+	UserProbation UserStatus = "probation"
+	UserRegular   UserStatus = "regular"
+	UserSuspended UserStatus = "suspended"
+
+	// Credibility Event
+	CredibilityReportResolved CredibilityEvent = "report_resolved"
+	CredibilityUpvoteReceived CredibilityEvent = "upvote_received"
+	CredibilitySpamConfirmed  CredibilityEvent = "spam_confirmed"
+	CredibilityReportHidden   CredibilityEvent = "report_hidden"
+	CredibilityManual         CredibilityEvent = "manual_adjustment"
+
 	// Error
 	ErrExist  = "exist"
 	ErrNoRows = "no rows in result set"